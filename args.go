@@ -1,7 +1,9 @@
 package rpclient
 
 import (
+	"log/slog"
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -64,6 +66,37 @@ func (a Args) Del(storeId string) Args {
 	return aa
 }
 
+// Failed 返回结果失败（Result.Ok 为 false）的查询及其结果在 reply.Results 中的位置
+//
+// 同一店铺的第 n 个查询对应该店铺的第 n 个结果，同一店铺中已成功的查询不会被返回；
+// 没有对应结果的查询无法确定是否已执行，不会被返回。重新调用后使用 Reply.MergeAt 合并结果。
+func (a Args) Failed(reply *Reply) (Args, []int) {
+	failed := Args{}
+	var slots []int
+	for i, j := range resultIndexes(a, reply.Results) {
+		if j >= 0 && !reply.Results[j].Ok {
+			failed = append(failed, a[i])
+			slots = append(slots, j)
+		}
+	}
+	return failed, slots
+}
+
+// Chunk 按 size 将查询拆分为多批，size 小于 1 时不拆分
+func (a Args) Chunk(size int) []Args {
+	if a.IsEmpty() {
+		return nil
+	}
+	if size < 1 {
+		size = len(a)
+	}
+	chunks := make([]Args, 0, (len(a)+size-1)/size)
+	for chunk := range slices.Chunk(a, size) {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
 // SetBody 设置所有存储的查询参数
 func (a Args) SetBody(body any) Args {
 	aa := make(Args, len(a))
@@ -90,3 +123,12 @@ func (a Args) SetStoreBody(storeId string, body any) Args {
 	}
 	return aa
 }
+
+// LogValue 实现 slog.LogValuer，记录日志时对每个查询脱敏，规则同 Payload.LogValue
+func (a Args) LogValue() slog.Value {
+	payloads := make([]Payload, len(a))
+	for i, p := range a {
+		payloads[i] = defaultRedactor().RedactPayload(p)
+	}
+	return slog.AnyValue(payloads)
+}
@@ -144,3 +144,17 @@ func TestArgs_isEmptyValue(t *testing.T) {
 		assert.False(t, isEmptyValue(v), "#%d", k)
 	}
 }
+
+func TestArgs_Chunk(t *testing.T) {
+	args := NewArgs()
+	assert.Nil(t, args.Chunk(2))
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		args = args.Add(NewPayload(Store{ID: id}))
+	}
+	chunks := args.Chunk(2)
+	assert.Equal(t, 3, len(chunks))
+	assert.Equal(t, 2, len(chunks[0]))
+	assert.Equal(t, 1, len(chunks[2]))
+	assert.Equal(t, "5", chunks[2][0].Store.ID)
+	assert.Equal(t, 1, len(args.Chunk(0)))
+}
@@ -0,0 +1,90 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PendingCall 异步调用，由 RpcClient.Go 返回
+type PendingCall struct {
+	ServiceMethod string
+	Args          Args
+	Reply         *Reply // 调用完成（Done 关闭）后才可读取
+
+	client *RpcClient
+	start  time.Time
+	err    error
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// Go invokes the service method asynchronously and returns a PendingCall
+// that completes when the call does.
+//
+// Unlike rpc.Client.Go, the call goes through CallContext, so
+// reply is reset, the timeout and retry options apply and the call is logged
+// with sensitive values masked. reply must not be accessed until the call is
+// done.
+func (c *RpcClient) Go(serviceMethod string, args Args, reply *Reply) *PendingCall {
+	ctx, cancel := context.WithCancel(context.Background())
+	call := &PendingCall{
+		ServiceMethod: serviceMethod,
+		Args:          args,
+		Reply:         reply,
+		client:        c,
+		start:         time.Now(),
+		done:          make(chan struct{}),
+		cancel:        cancel,
+	}
+	go func() {
+		defer cancel()
+		call.err = c.CallContext(ctx, serviceMethod, args, reply)
+		close(call.done)
+	}()
+	return call
+}
+
+// Done 调用完成时关闭
+func (pc *PendingCall) Done() <-chan struct{} {
+	return pc.done
+}
+
+// Err 调用结果，调用未完成时返回 nil
+func (pc *PendingCall) Err() error {
+	select {
+	case <-pc.done:
+		return pc.err
+	default:
+		return nil
+	}
+}
+
+// Wait 等待调用完成并返回其错误
+//
+// ctx 结束时只停止等待，调用继续执行，可以再次 Wait 或通过 Cancel 放弃调用；
+// 超过截止时间返回 rrse.TimeOut 类型的 *TimeoutError，StoreIds 的含义同同步调用。
+func (pc *PendingCall) Wait(ctx context.Context) error {
+	select {
+	case <-pc.done:
+		return pc.err
+	case <-ctx.Done():
+		return pc.client.contextError(ctx, pc.ServiceMethod, pc.Args, pc.start)
+	}
+}
+
+// Cancel 放弃调用，调用完成后 Err 返回 context.Canceled 相关的错误；调用已完成时不做任何操作
+func (pc *PendingCall) Cancel() {
+	pc.cancel()
+}
+
+// WaitAll 等待所有调用完成，返回所有错误的合并结果
+func WaitAll(ctx context.Context, calls ...*PendingCall) error {
+	errs := make([]error, 0, len(calls))
+	for _, call := range calls {
+		if err := call.Wait(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package rpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRpcClient_Go(t *testing.T) {
+	ts := newTestServer(t)
+	client := newTestClient(t, ts.addr, nil)
+
+	// 两个调用并发执行，顺序执行至少需要 2 秒
+	var r1, r2 Reply
+	start := time.Now()
+	c1 := client.Go("Test.Sleep", NewArgs().Add(NewPayload(testStore("1"), 1000)), &r1)
+	c2 := client.Go("Test.Sleep", NewArgs().Add(NewPayload(testStore("2"), 1000)), &r2)
+	assert.NoError(t, WaitAll(context.Background(), c1, c2))
+	assert.Less(t, time.Since(start), 1900*time.Millisecond)
+	assert.Equal(t, "1", r1.Results[0].StoreId)
+	assert.Equal(t, "2", r2.Results[0].StoreId)
+
+	select {
+	case <-c1.Done():
+	default:
+		t.Fatal("expected call to be done")
+	}
+	assert.NoError(t, c1.Err())
+}
+
+func TestPendingCall_Wait(t *testing.T) {
+	ts := newTestServer(t)
+	client := newTestClient(t, ts.addr, nil)
+
+	// Wait 超时只停止等待，不影响调用本身
+	s3 := testStore("3")
+	s3.Timeout = 1
+	var r3 Reply
+	c3 := client.Go("Test.Sleep", NewArgs().Add(NewPayload(s3, 1200)), &r3)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c3.Wait(ctx)
+	assert.True(t, rrse.Is(rrse.TimeOut, err))
+	assert.NoError(t, c3.Err())
+	assert.NoError(t, c3.Wait(context.Background()))
+	assert.Equal(t, 1, len(r3.Results))
+
+	// 超出店铺自身超时时间时，TimeoutError 中包含店铺 ID
+	c3 = client.Go("Test.Sleep", NewArgs().Add(NewPayload(s3, 1500)), &r3)
+	ctx, cancel = context.WithTimeout(context.Background(), 1100*time.Millisecond)
+	defer cancel()
+	te, ok := AsTimeoutError(c3.Wait(ctx))
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"3"}, te.StoreIds)
+	}
+	assert.NoError(t, c3.Wait(context.Background()))
+
+	// Cancel 放弃调用
+	var r4 Reply
+	c4 := client.Go("Test.Sleep", NewArgs().Add(NewPayload(testStore("4"), 1000)), &r4)
+	c4.Cancel()
+	err = c4.Wait(context.Background())
+	assert.ErrorIs(t, cause(err), context.Canceled)
+	assert.Equal(t, 0, len(r4.Results))
+}
@@ -0,0 +1,45 @@
+package rpclient
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff 指数退避配置，整个配置为零值时使用 defaultBackoff
+//
+// 设置了任一字段时按字段取值：Max 为 0 表示不限制，Multiplier 小于 1 时为固定间隔，Jitter 为 0 表示不抖动。
+type Backoff struct {
+	Initial    int     `json:"initial" yaml:"initial" toml:"initial"`          // 首次等待时间（毫秒）
+	Max        int     `json:"max" yaml:"max" toml:"max"`                      // 最长等待时间（毫秒），0 表示不限制
+	Multiplier float64 `json:"multiplier" yaml:"multiplier" toml:"multiplier"` // 等待时间的增长倍数，小于 1 时为固定间隔
+	Jitter     float64 `json:"jitter" yaml:"jitter" toml:"jitter"`             // 随机抖动比例，取值 0 ~ 1，0 表示不抖动
+}
+
+var defaultBackoff = Backoff{
+	Initial:    100,
+	Max:        10000,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// Duration 第 attempt 次（从 0 开始）重试前的等待时间
+func (b Backoff) Duration(attempt int) time.Duration {
+	if b == (Backoff{}) {
+		b = defaultBackoff
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 1
+	}
+	b.Jitter = math.Min(math.Max(b.Jitter, 0), 1)
+
+	d := float64(max(b.Initial, 0)) * math.Pow(b.Multiplier, float64(attempt))
+	if b.Max > 0 {
+		d = math.Min(d, float64(b.Max))
+	}
+	// 避免不限制最长等待时间时溢出
+	d = math.Min(d, float64(math.MaxInt64/int64(time.Millisecond)/2))
+	// 在 [d * (1 - jitter), d * (1 + jitter)) 范围内随机
+	d *= 1 + b.Jitter*(2*rand.Float64()-1)
+	return time.Duration(d * float64(time.Millisecond))
+}
@@ -0,0 +1,38 @@
+package rpclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Duration(t *testing.T) {
+	b := Backoff{Initial: 100, Max: 1000, Multiplier: 2, Jitter: 0.1}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, 1000 * time.Millisecond},
+		{10, 1000 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		d := b.Duration(tt.attempt)
+		assert.GreaterOrEqualf(t, d, tt.want*9/10, "attempt %d", tt.attempt)
+		assert.LessOrEqualf(t, d, tt.want*11/10, "attempt %d", tt.attempt)
+	}
+
+	// 零值使用默认配置
+	d := Backoff{}.Duration(0)
+	assert.GreaterOrEqual(t, d, 80*time.Millisecond)
+	assert.LessOrEqual(t, d, 120*time.Millisecond)
+
+	// 设置了任一字段时按字段取值，Jitter 为 0 表示不抖动
+	assert.Equal(t, 100*time.Millisecond, Backoff{Initial: 100, Max: 1000}.Duration(5))
+	assert.Equal(t, 1000*time.Millisecond, Backoff{Initial: 100, Max: 1000, Multiplier: 2}.Duration(5))
+	assert.Equal(t, 3200*time.Millisecond, Backoff{Initial: 100, Multiplier: 2}.Duration(5))
+	assert.Greater(t, Backoff{Initial: 100, Multiplier: 2}.Duration(1000), time.Duration(0))
+}
@@ -0,0 +1,94 @@
+package rpclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ChunkError 分批调用中单个批次的错误
+type ChunkError struct {
+	Index    int      // 批次序号，从 0 开始
+	StoreIds []string // 批次中的店铺 ID
+	Err      error    // 调用错误
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("rpclient: chunk %d (stores %s): %v", e.Index, strings.Join(e.StoreIds, ", "), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// ChunkErrors 分批调用中失败批次的错误集合
+type ChunkErrors []*ChunkError
+
+func (e ChunkErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// StoreIds 失败批次中的所有店铺 ID
+func (e ChunkErrors) StoreIds() []string {
+	var storeIds []string
+	for _, err := range e {
+		storeIds = append(storeIds, err.StoreIds...)
+	}
+	return storeIds
+}
+
+// CallChunked splits args into chunks of chunkSize payloads and calls them
+// through CallContext, running at most concurrency chunks at the same time.
+//
+// The results of all successful chunks are merged into one Reply in the order
+// of args. The RequestId of the last chunk becomes Reply.RequestId, the others
+// are kept in Reply.PreviousRequestIds in the same way as Reply.MergeAt. A transport error fails only its own
+// chunk: the returned error is then a ChunkErrors listing every failed chunk
+// together with its stores, and the returned Reply still holds the results of
+// the other chunks.
+func (c *RpcClient) CallChunked(ctx context.Context, serviceMethod string, args Args, chunkSize, concurrency int) (*Reply, error) {
+	chunks := args.Chunk(chunkSize)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	replies := make([]Reply, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = c.CallContext(ctx, serviceMethod, chunk, &replies[i])
+		}()
+	}
+	wg.Wait()
+
+	reply := new(Reply).Reset()
+	var chunkErrs ChunkErrors
+	for i, r := range replies {
+		if errs[i] != nil {
+			storeIds := make([]string, len(chunks[i]))
+			for k, payload := range chunks[i] {
+				storeIds[k] = payload.Store.ID
+			}
+			chunkErrs = append(chunkErrs, &ChunkError{Index: i, StoreIds: storeIds, Err: errs[i]})
+			continue
+		}
+		// 不同批次的店铺可能重复，结果只追加不替换
+		reply.mergeRequestId(&replies[i])
+		reply.Results = append(reply.Results, r.Results...)
+	}
+	if len(chunkErrs) > 0 {
+		return reply, chunkErrs
+	}
+	return reply, nil
+}
@@ -0,0 +1,52 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRpcClient_CallChunked(t *testing.T) {
+	ts := newTestServer(t)
+	client := newTestClient(t, ts.addr, nil)
+
+	args := NewArgs()
+	for i := 0; i < 10; i++ {
+		args = args.Add(NewPayload(testStore(fmt.Sprintf("%d", i)), 100))
+	}
+	start := time.Now()
+	reply, err := client.CallChunked(context.Background(), "Test.Sleep", args, 2, 5)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, 10, len(reply.Results))
+	for i, result := range reply.Results {
+		assert.Equal(t, fmt.Sprintf("%d", i), result.StoreId)
+	}
+	assert.NotEmpty(t, reply.RequestId)
+	assert.Equal(t, 4, len(reply.PreviousRequestIds))
+
+	// 超时的批次单独报告，不影响其他批次
+	args = args.SetStoreBody("3", 2000)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err = client.CallChunked(ctx, "Test.Sleep", args, 2, 5)
+	assert.Error(t, err)
+	var chunkErrs ChunkErrors
+	if assert.True(t, errors.As(err, &chunkErrs)) {
+		assert.Equal(t, 1, len(chunkErrs))
+		assert.Equal(t, 1, chunkErrs[0].Index)
+		assert.Equal(t, []string{"2", "3"}, chunkErrs.StoreIds())
+	}
+	assert.Equal(t, 8, len(reply.Results))
+
+	// 同一店铺分在不同批次时保留每个结果
+	args = NewArgs().Add(NewPayload(testStore("1"), 1)).Add(NewPayload(testStore("1"), 2))
+	reply, err = client.CallChunked(context.Background(), "Test.Echo", args, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(reply.Results))
+	assert.Equal(t, 1, len(reply.PreviousRequestIds))
+}
@@ -0,0 +1,322 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+	"gopkg.in/guregu/null.v4"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 正常调用
+	CircuitOpen                         // 熔断，调用直接返回 ErrCircuitOpen
+	CircuitHalfOpen                     // 冷却结束，放行一次试探调用
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerOption 熔断配置
+//
+// 熔断按服务方法及店铺（Store.ID）分别计数，一个店铺的平台接口不可用时不影响其他店铺。
+type CircuitBreakerOption struct {
+	Enabled        bool                                                       `json:"enabled" yaml:"enabled" toml:"enabled"`                         // 是否启用熔断
+	Threshold      int                                                        `json:"threshold" yaml:"threshold" toml:"threshold"`                   // 连续失败多少次后熔断，默认 5
+	Cooldown       int                                                        `json:"cooldown" yaml:"cooldown" toml:"cooldown"`                      // 熔断后进入半开状态前的等待时间（毫秒），默认 30000
+	ServiceMethods []string                                                   `json:"service_methods" yaml:"service_methods" toml:"service_methods"` // 启用熔断的服务方法，规则同 RetryOption.Idempotent，为空表示全部
+	OnStateChange  func(serviceMethod, storeId string, from, to CircuitState) `json:"-" yaml:"-" toml:"-"`                                           // 状态变化时调用
+}
+
+var defaultCircuitBreakerOption = CircuitBreakerOption{
+	Threshold: 5,
+	Cooldown:  30000,
+}
+
+// ErrCircuitOpen 熔断器处于打开状态，调用未发出
+var ErrCircuitOpen = errors.New("rpclient: circuit breaker is open")
+
+// CircuitOpenError 熔断错误，args 中所有店铺均处于熔断状态时返回
+//
+// errors.Is(err, ErrCircuitOpen) 为 true。
+type CircuitOpenError struct {
+	ServiceMethod string    // 服务方法
+	StoreIds      []string  // 处于熔断状态的店铺 ID
+	RetryAt       time.Time // 最早进入半开状态的时间
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("rpclient: circuit breaker is open for %s, stores: %s", e.ServiceMethod, strings.Join(e.StoreIds, ", "))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// AsCircuitOpenError 从调用返回的错误中提取 *CircuitOpenError
+func AsCircuitOpenError(err error) (*CircuitOpenError, bool) {
+	var ce *CircuitOpenError
+	if errors.As(cause(err), &ce) {
+		return ce, true
+	}
+	return nil, false
+}
+
+// circuitKey 熔断计数的键
+type circuitKey struct {
+	serviceMethod string
+	storeId       string
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int       // 连续失败次数
+	retryAt  time.Time // 打开状态下进入半开状态的时间
+	probing  bool      // 半开状态下是否已放行试探调用
+}
+
+// stateChange 待通知的状态变化
+type stateChange struct {
+	key      circuitKey
+	from, to CircuitState
+}
+
+// CircuitBreaker 按服务方法及店铺熔断的调用拦截器
+//
+// 连续 Threshold 次 Result.Ok == false 或传输错误后熔断，Cooldown 后进入半开状态，
+// 放行一次试探调用：成功则恢复，失败则继续熔断。
+// 传输错误只包括连接断开、超时（*TimeoutError）及网络错误（net.Error），
+// 主动取消、服务端返回的错误（rpc.ServerError）及其他错误不计数。
+//
+// 处于熔断状态的店铺不发出调用，无论是部分还是全部店铺熔断，reply 中都会为每个熔断的店铺
+// 追加一个 Ok 为 false、Error 为 ErrCircuitOpen 的 Result：
+//   - 部分店铺熔断时只为其余店铺发起调用，返回该调用的错误，熔断的店铺只通过 Result 体现
+//   - 所有店铺均熔断时不发起调用，reply 中只有熔断店铺的 Result，并返回 *CircuitOpenError
+type CircuitBreaker struct {
+	option CircuitBreakerOption
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	circuits map[circuitKey]*circuit
+}
+
+// NewCircuitBreaker 创建熔断器，返回值的 Intercept 方法可以作为 UnaryInterceptor 使用
+func NewCircuitBreaker(opt CircuitBreakerOption) *CircuitBreaker {
+	return newCircuitBreaker(opt, nil)
+}
+
+func newCircuitBreaker(opt CircuitBreakerOption, logger *slog.Logger) *CircuitBreaker {
+	if opt.Threshold <= 0 {
+		opt.Threshold = defaultCircuitBreakerOption.Threshold
+	}
+	if opt.Cooldown <= 0 {
+		opt.Cooldown = defaultCircuitBreakerOption.Cooldown
+	}
+	return &CircuitBreaker{
+		option:   opt,
+		logger:   logger,
+		circuits: make(map[circuitKey]*circuit),
+	}
+}
+
+// State 返回服务方法及店铺的熔断状态
+func (b *CircuitBreaker) State(serviceMethod, storeId string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb, ok := b.circuits[circuitKey{serviceMethod, storeId}]
+	if !ok {
+		return CircuitClosed
+	}
+	if cb.state == CircuitOpen && !time.Now().Before(cb.retryAt) {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+// Intercept 实现 UnaryInterceptor
+func (b *CircuitBreaker) Intercept(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+	if len(args) == 0 || (len(b.option.ServiceMethods) > 0 && !matchServiceMethod(b.option.ServiceMethods, serviceMethod)) {
+		return invoker(ctx, serviceMethod, args, reply)
+	}
+
+	allowed, rejected, retryAt := b.allow(serviceMethod, args)
+	if len(allowed) == 0 {
+		reply.Reset()
+		appendRejected(reply, rejected)
+		return rrse.E(rrse.Op("circuit_breaker"), &CircuitOpenError{
+			ServiceMethod: serviceMethod,
+			StoreIds:      storeIds(rejected),
+			RetryAt:       retryAt,
+		})
+	}
+
+	err := invoker(ctx, serviceMethod, allowed, reply)
+	b.record(serviceMethod, allowed, reply, err)
+	appendRejected(reply, rejected)
+	return err
+}
+
+// appendRejected 为熔断的店铺追加 Ok 为 false 的 Result
+func appendRejected(reply *Reply, rejected Args) {
+	for _, p := range rejected {
+		reply.Results = append(reply.Results, Result{
+			StoreId:   p.Store.ID,
+			StoreName: p.Store.Name,
+			Ok:        false,
+			Error:     null.StringFrom(ErrCircuitOpen.Error()),
+		})
+	}
+}
+
+// allow 将 args 分为放行及熔断的店铺，返回熔断店铺中最早进入半开状态的时间
+func (b *CircuitBreaker) allow(serviceMethod string, args Args) (allowed, rejected Args, retryAt time.Time) {
+	var changes []stateChange
+	defer func() {
+		b.notify(changes)
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for _, p := range args {
+		key := circuitKey{serviceMethod, p.Store.ID}
+		cb, ok := b.circuits[key]
+		if !ok || cb.state == CircuitClosed {
+			allowed = append(allowed, p)
+			continue
+		}
+		if cb.state == CircuitOpen && !now.Before(cb.retryAt) {
+			cb.state, cb.probing = CircuitHalfOpen, false
+			changes = append(changes, stateChange{key, CircuitOpen, CircuitHalfOpen})
+		}
+		if cb.state == CircuitHalfOpen && !cb.probing {
+			cb.probing = true
+			allowed = append(allowed, p)
+			continue
+		}
+		rejected = append(rejected, p)
+		if retryAt.IsZero() || cb.retryAt.Before(retryAt) {
+			retryAt = cb.retryAt
+		}
+	}
+	return allowed, rejected, retryAt
+}
+
+// record 按调用结果更新熔断计数
+//
+// 传输错误计为 args 中所有店铺失败；调用成功时按店铺的 Result.Ok 计数，其他错误不计数。
+func (b *CircuitBreaker) record(serviceMethod string, args Args, reply *Reply, err error) {
+	outcomes := make(map[string]bool, len(args))
+	switch {
+	case err == nil:
+		for _, result := range reply.Results {
+			ok, seen := outcomes[result.StoreId]
+			outcomes[result.StoreId] = result.Ok && (ok || !seen)
+		}
+	case isTransportError(err):
+		for _, p := range args {
+			outcomes[p.Store.ID] = false
+		}
+	}
+
+	var changes []stateChange
+	defer func() {
+		b.notify(changes)
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, p := range args {
+		succeeded, counted := outcomes[p.Store.ID]
+		// 同一店铺只计数一次
+		delete(outcomes, p.Store.ID)
+		key := circuitKey{serviceMethod, p.Store.ID}
+		cb, ok := b.circuits[key]
+		switch {
+		case !counted:
+			if ok {
+				cb.probing = false
+			}
+		case succeeded:
+			if ok && cb.state != CircuitClosed {
+				changes = append(changes, stateChange{key, cb.state, CircuitClosed})
+			}
+			delete(b.circuits, key)
+		default:
+			if !ok {
+				cb = &circuit{}
+				b.circuits[key] = cb
+			}
+			cb.failures++
+			if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= b.option.Threshold) {
+				changes = append(changes, stateChange{key, cb.state, CircuitOpen})
+				cb.state, cb.probing = CircuitOpen, false
+				cb.retryAt = time.Now().Add(time.Duration(b.option.Cooldown) * time.Millisecond)
+			}
+		}
+	}
+}
+
+// notify 记录日志并调用 OnStateChange，调用方不能持有 b.mu
+func (b *CircuitBreaker) notify(changes []stateChange) {
+	for _, change := range changes {
+		if b.logger != nil {
+			b.logger.Warn("CircuitBreaker", "serviceMethod", change.key.serviceMethod, "storeId", change.key.storeId, "from", change.from.String(), "to", change.to.String())
+		}
+		if b.option.OnStateChange != nil {
+			b.option.OnStateChange(change.key.serviceMethod, change.key.storeId, change.from, change.to)
+		}
+	}
+}
+
+// CircuitBreaker 返回客户端的熔断器，未启用 Option.CircuitBreaker 时返回 nil
+func (c *RpcClient) CircuitBreaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// CircuitBreaker 返回所有连接共享的熔断器，未启用 Option.CircuitBreaker 时返回 nil
+func (p *Pool) CircuitBreaker() *CircuitBreaker {
+	return p.breaker
+}
+
+// CircuitBreaker 返回所有端点共享的熔断器，未启用 Option.CircuitBreaker 时返回 nil
+func (c *ClusterClient) CircuitBreaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// isTransportError 判断错误是否计为熔断失败：连接断开、超时及网络错误
+func isTransportError(err error) bool {
+	if isConnError(err) {
+		return true
+	}
+	if _, ok := AsTimeoutError(err); ok {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(cause(err), &netErr)
+}
+
+func storeIds(args Args) []string {
+	ids := make([]string, 0, len(args))
+	for _, p := range args {
+		ids = append(ids, p.Store.ID)
+	}
+	return ids
+}
@@ -0,0 +1,172 @@
+package rpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// stateRecorder 记录熔断器的状态变化
+type stateRecorder struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (r *stateRecorder) record(serviceMethod, storeId string, from, to CircuitState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, serviceMethod+"/"+storeId+": "+from.String()+" -> "+to.String())
+}
+
+func (r *stateRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.changes...)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ts := newTestServer(t)
+	recorder := &stateRecorder{}
+	opt := testOption
+	opt.CircuitBreaker = CircuitBreakerOption{Enabled: true, Threshold: 2, Cooldown: 100, OnStateChange: recorder.record}
+	client := newTestClient(t, ts.addr, &opt)
+	breaker := client.CircuitBreaker()
+
+	failing := testStore("1")
+	failing.Configuration["fail"] = true
+	args := NewArgs().Add(NewPayload(failing, "a")).Add(NewPayload(testStore("2"), "b"))
+
+	var r Reply
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, client.Call("Test.Echo", args, &r))
+		assert.Len(t, r.Results, 2)
+	}
+	assert.Equal(t, CircuitOpen, breaker.State("Test.Echo", "1"))
+	assert.Equal(t, CircuitClosed, breaker.State("Test.Echo", "2"))
+	assert.Equal(t, CircuitClosed, breaker.State("Test.Sleep", "1"))
+
+	// 熔断的店铺不发出调用，在 reply 中追加失败的 Result
+	assert.NoError(t, client.Call("Test.Echo", args, &r))
+	if assert.Len(t, r.Results, 2) {
+		assert.Equal(t, "2", r.Results[0].StoreId)
+		assert.True(t, r.Results[0].Ok)
+		assert.Equal(t, "1", r.Results[1].StoreId)
+		assert.False(t, r.Results[1].Ok)
+		assert.Equal(t, ErrCircuitOpen.Error(), r.Results[1].Error.String)
+	}
+
+	err := client.Call("Test.Echo", args[:1], &r)
+	assert.True(t, errors.Is(cause(err), ErrCircuitOpen), err)
+	ce, ok := AsCircuitOpenError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Test.Echo", ce.ServiceMethod)
+		assert.Equal(t, []string{"1"}, ce.StoreIds)
+		assert.False(t, ce.RetryAt.IsZero())
+	}
+	// 全部熔断时同样为每个店铺填充失败的 Result
+	if assert.Len(t, r.Results, 1) {
+		assert.Equal(t, "1", r.Results[0].StoreId)
+		assert.False(t, r.Results[0].Ok)
+		assert.Equal(t, ErrCircuitOpen.Error(), r.Results[0].Error.String)
+	}
+
+	// 冷却后放行试探调用，失败则继续熔断
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, breaker.State("Test.Echo", "1"))
+	assert.NoError(t, client.Call("Test.Echo", args[:1], &r))
+	assert.Equal(t, CircuitOpen, breaker.State("Test.Echo", "1"))
+
+	// 试探调用成功则恢复
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), "a")), &r))
+	assert.Equal(t, CircuitClosed, breaker.State("Test.Echo", "1"))
+
+	assert.Equal(t, []string{
+		"Test.Echo/1: closed -> open",
+		"Test.Echo/1: open -> half_open",
+		"Test.Echo/1: half_open -> open",
+		"Test.Echo/1: open -> half_open",
+		"Test.Echo/1: half_open -> closed",
+	}, recorder.get())
+}
+
+func TestCircuitBreaker_TransportError(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerOption{Threshold: 2, ServiceMethods: []string{"Temu.*"}})
+	args := NewArgs().Add(NewPayload(testStore("1")))
+	var calls int
+	invoke := func(err error) Invoker {
+		return func(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+			calls++
+			return err
+		}
+	}
+
+	// 主动取消、服务端返回的错误及其他错误不计数
+	var r Reply
+	for _, err := range []error{
+		context.Canceled,
+		rpc.ServerError("bad request"),
+		ErrNotReady,
+		errors.New("rpclient: invalid args"),
+		&json.UnmarshalTypeError{Value: "string"},
+	} {
+		_ = breaker.Intercept(context.Background(), "Temu.Order", args, &r, invoke(rrse.E(rrse.Op("call"), err)))
+	}
+	assert.Equal(t, CircuitClosed, breaker.State("Temu.Order", "1"))
+
+	// 连接断开、超时及网络错误计数
+	for _, err := range []error{
+		io.EOF,
+		rrse.E(rrse.TimeOut, &TimeoutError{ServiceMethod: "Temu.Order", Err: context.DeadlineExceeded}),
+		&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")},
+	} {
+		breaker := NewCircuitBreaker(CircuitBreakerOption{Threshold: 1})
+		_ = breaker.Intercept(context.Background(), "Temu.Order", args, &r, invoke(rrse.E(rrse.Op("call"), err)))
+		assert.Equal(t, CircuitOpen, breaker.State("Temu.Order", "1"), err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_ = breaker.Intercept(context.Background(), "Temu.Order", args, &r, invoke(rrse.E(rrse.Op("call"), io.EOF)))
+	}
+	assert.Equal(t, CircuitOpen, breaker.State("Temu.Order", "1"))
+	calls = 0
+	err := breaker.Intercept(context.Background(), "Temu.Order", args, &r, invoke(nil))
+	assert.True(t, errors.Is(cause(err), ErrCircuitOpen), err)
+	assert.Equal(t, 0, calls)
+
+	// 未匹配 ServiceMethods 的服务方法不熔断
+	for i := 0; i < 3; i++ {
+		_ = breaker.Intercept(context.Background(), "Shein.Order", args, &r, invoke(io.EOF))
+	}
+	assert.Equal(t, CircuitClosed, breaker.State("Shein.Order", "1"))
+	assert.Equal(t, 3, calls)
+}
+
+func TestCircuitBreaker_Pool(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.CircuitBreaker = CircuitBreakerOption{Enabled: true, Threshold: 1}
+	pool, err := NewPool(ts.addr, &opt, &PoolOption{MinConns: 2, MaxConns: 2, Strategy: RoundRobin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	failing := testStore("1")
+	failing.Configuration["fail"] = true
+	var r Reply
+	assert.NoError(t, pool.Call("Test.Echo", NewArgs().Add(NewPayload(failing)), &r))
+	// 熔断器在连接之间共享
+	err = pool.Call("Test.Echo", NewArgs().Add(NewPayload(failing)), &r)
+	assert.True(t, errors.Is(cause(err), ErrCircuitOpen), err)
+	assert.Equal(t, CircuitOpen, pool.CircuitBreaker().State("Test.Echo", "1"))
+}
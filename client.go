@@ -1,17 +1,18 @@
 package rpclient
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"os"
-	"slices"
-	"strings"
+	"sync"
+	"time"
 
 	rrse "github.com/roadrunner-server/errors"
-	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
 )
 
 const (
@@ -21,33 +22,61 @@ const (
 )
 
 type RpcClient struct {
-	*rpc.Client
+	addr   string
 	logger *slog.Logger
 	option *Option
+
+	mu         sync.RWMutex
+	client     *rpc.Client // 重连时替换，只能通过 readyClient 获取
+	state      State
+	done       chan struct{}   // Close 时关闭，用于停止重连
+	ready      chan struct{}   // 离开 StateConnecting 时关闭，重连时重新创建
+	idempotent []string        // 可安全重试的服务方法
+	invoker    Invoker         // 包含拦截器的调用链
+	redactor   *Redactor       // 日志脱敏
+	logStats   logStats        // 日志采样及截断计数
+	tlsConfig  *tls.Config     // 为 nil 时不使用 TLS
+	breaker    *CircuitBreaker // 为 nil 时不熔断
 }
 
-func maskString(s string) string {
-	n := len(s)
-	if n <= 2 {
-		return strings.Repeat("*", n)
+// newLogger 创建带有 rpclient 分组及 dsn、codec 属性的日志记录器
+// 优先使用 Option.Logger、Option.LogHandler，均未设置时输出 JSON 格式日志到标准输出
+func newLogger(addr string, opt *Option) *slog.Logger {
+	attrs := []any{
+		"dsn",
+		fmt.Sprintf("%s://%s", opt.Network, addr),
+		"codec",
+		opt.Codec,
+	}
+	switch {
+	case opt.Logger != nil:
+		return opt.Logger.WithGroup("rpclient").With(attrs...)
+	case opt.LogHandler != nil:
+		return slog.New(opt.LogHandler).WithGroup("rpclient").With(attrs...)
 	}
 
-	var keep int
-	if n <= 6 {
-		keep = n / 2
-	} else {
-		// 对于长字符串，保留前 25% 和后 25%
-		keep = n / 4
-		if keep < 3 {
-			keep = 3
-		}
+	logLevel := slog.LevelDebug
+	switch opt.LogLevel {
+	case "info":
+		logLevel = slog.LevelInfo
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
 	}
-	return s[:keep] + strings.Repeat("*", n-keep*2) + s[n-keep:]
+	return slog.
+		New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource: false,
+			Level:     logLevel,
+		})).
+		WithGroup("rpclient").
+		With(append(attrs, "log_level", logLevel.String())...)
 }
 
 // NewClient creates a new RPC client to the given address.
 //
-// The address should be given in the format "host:port".
+// The address should be given in the format "host:port", or the socket path
+// for the "unix" network.
 //
 // The opt parameter is an optional Option pointer that can be used to specify
 // the network type and codec to be used. If nil, the defaultOption is used.
@@ -55,104 +84,254 @@ func maskString(s string) string {
 // The method returns a pointer to a new RpcClient and an error. The RpcClient
 // is a simple wrapper around the rpc.Client and net.Conn. The Error field of
 // the RpcClient is set to the error returned by the underlying Close methods.
+// The rpc.Client is replaced on reconnect and therefore no longer embedded;
+// the deprecated Client method returns the current one. Use CallRaw for
+// arguments that are not Args.
 //
-// The supported codecs are "goridge" and "json". The default codec is "json".
+// The supported codecs are "goridge", "json" and "msgpack". The default codec is "json".
+// An opt rejected by Option.Validate is returned as an error instead of falling
+// back to defaults.
+//
+// Once the connection is lost (rpc.ErrShutdown, io.EOF), the client redials
+// addr in the background with exponential backoff, see Option.Reconnect.
 func NewClient(addr string, opt *Option) (*RpcClient, error) {
+	return newClient(context.Background(), addr, opt)
+}
+
+// newClient 同 NewClient，建立连接同时受 ctx 限制，用于连接池、集群按调用建立连接
+func newClient(ctx context.Context, addr string, opt *Option) (*RpcClient, error) {
 	if opt == nil {
 		opt = &defaultOption
 	}
-	logLevel := slog.LevelDebug
-	switch opt.LogLevel {
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
+	if err := opt.Validate(); err != nil {
+		return nil, rrse.E(rrse.Op("new_client"), err)
 	}
-	logger := slog.
-		New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			AddSource: false,
-			Level:     logLevel,
-		})).
-		WithGroup("rpclient").
-		With(
-			"dsn",
-			fmt.Sprintf("%s://%s", opt.Network, addr),
-			"codec",
-			opt.Codec,
-			"log_level",
-			logLevel.String(),
-		)
-	conn, err := net.Dial(opt.Network, addr)
+	opt = opt.withDefaults()
+	redactor, err := newRedactor(opt)
+	if err != nil {
+		return nil, rrse.E(rrse.Op("new_client"), err)
+	}
+	tlsConfig, err := opt.TLS.config(addr)
+	if err != nil {
+		return nil, rrse.E(rrse.Op("new_client"), err)
+	}
+	c := &RpcClient{
+		addr:   addr,
+		logger: newLogger(addr, opt),
+		option: opt,
+		state:  StateConnecting,
+		done:   make(chan struct{}),
+		ready:  make(chan struct{}),
+
+		idempotent: append([]string{}, opt.Retry.Idempotent...),
+		redactor:   redactor,
+		tlsConfig:  tlsConfig,
+	}
+	interceptors := []UnaryInterceptor{c.loggingInterceptor}
+	if opt.CircuitBreaker.Enabled {
+		c.breaker = newCircuitBreaker(opt.CircuitBreaker, c.logger)
+		interceptors = append(interceptors, c.breaker.Intercept)
+	}
+	c.invoker = chainInvoker(append(interceptors, opt.Interceptors...), c.invokeWithRetry)
+	client, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	c.state = StateReady
+	close(c.ready)
+	return c, nil
+}
+
+// dial 建立连接并创建 rpc.Client
+func (c *RpcClient) dial(ctx context.Context) (*rpc.Client, error) {
+	conn, err := c.dialConn(ctx)
 	if err != nil {
-		logger.Error("Dial", "error", err)
+		c.logger.Error("Dial", "error", err)
 		return nil, rrse.E(rrse.Op("dial"), err)
 	}
 
-	logger.Debug("Dial", "error", nil)
+	c.logger.Debug("Dial", "error", nil)
 	var clientCodec rpc.ClientCodec
-	if opt.Codec == GoridgeCodec {
-		clientCodec = goridgeRpc.NewClientCodec(conn)
-	} else {
+	switch c.option.Codec {
+	case GoridgeCodec:
+		if clientCodec, err = newGoridgeClientCodec(conn, c.option.Goridge); err != nil {
+			_ = conn.Close()
+			c.logger.Error("Dial", "error", err)
+			return nil, rrse.E(rrse.Op("dial"), err)
+		}
+	case MsgpackCodec:
+		clientCodec = newMsgpackClientCodec(conn)
+	default:
 		clientCodec = jsonrpc.NewClientCodec(conn)
 	}
-	return &RpcClient{
-		Client: rpc.NewClientWithCodec(clientCodec),
-		logger: logger,
-		option: opt,
-	}, nil
+	return rpc.NewClientWithCodec(clientCodec), nil
 }
 
 // Call calls the RPC server with the given service method and arguments.
 // It returns an error if the call fails.
 func (c *RpcClient) Call(serviceMethod string, args Args, reply *Reply) error {
+	return c.CallContext(context.Background(), serviceMethod, args, reply)
+}
+
+// CallContext is like Call but honours the cancellation and deadline of ctx.
+//
+// Besides the deadline of ctx, the call is bounded by the largest Store.Timeout
+// in args (Option.Timeout for stores without one) plus Option.TimeoutOverhead.
+// If any store in args has neither, the call has no deadline of its own.
+// Transport failures are retried within that bound according to Option.Retry,
+// but only for service methods registered as idempotent.
+//
+// The call passes through the default logging interceptor, the circuit breaker
+// when Option.CircuitBreaker is enabled, and then through Option.Interceptors
+// in order; retries happen inside the innermost invoker.
+//
+// The call is issued through rpc.Client.Go. If ctx is done before the server
+// answers, the call is abandoned: a *TimeoutError of kind rrse.TimeOut is
+// returned when the deadline was exceeded, the context error otherwise. A late
+// answer of an abandoned call is discarded and never written into reply.
+func (c *RpcClient) CallContext(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
 	reply.Reset()
-	err := c.Client.Call(serviceMethod, args, reply)
-	if err != nil {
-		err = rrse.E(rrse.Op("call"), err)
+	ctx = withCallStart(ctx)
+	if timeout := c.callTimeout(args); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
+	return c.invoker(ctx, serviceMethod, args, reply)
+}
 
-	sanitizedArgs := make([]Payload, len(args))
-	for i, arg := range args {
-		cfg := make(Configuration, len(arg.Store.Configuration))
-		for key, value := range arg.Store.Configuration {
-			if slices.Index(c.option.SensitiveWords, key) != -1 {
-				switch value.(type) {
-				case string:
-					value = maskString(value.(string))
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					value = maskString(fmt.Sprintf("%d", value))
-				}
+// invoke 发起调用并等待结果或 ctx 结束，正在重连时先等待重连完成（ctx 没有截止时间时返回 ErrNotReady）
+func (c *RpcClient) invoke(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	start := callStart(ctx)
+	client, err := c.readyClient(ctx)
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		return c.contextError(ctx, serviceMethod, args, start)
+	default:
+		return rrse.E(rrse.Op("call"), rrse.Network, err)
+	}
+
+	// 使用独立的 Reply 接收数据，避免被放弃的调用在返回后继续写入 reply
+	r := new(Reply).Reset()
+	call := client.Go(serviceMethod, args, r, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			if isConnError(call.Error) {
+				c.reconnect(client)
 			}
-			cfg[key] = value
+			return rrse.E(rrse.Op("call"), call.Error)
 		}
-		sanitizedArg := Payload{
-			Store: arg.Store,
-			Body:  arg.Body,
+		*reply = *r
+		return nil
+	case <-ctx.Done():
+		return c.contextError(ctx, serviceMethod, args, start)
+	}
+}
+
+// contextError 返回 ctx 结束时的错误，超过截止时间时返回 rrse.TimeOut 类型的 *TimeoutError
+func (c *RpcClient) contextError(ctx context.Context, serviceMethod string, args Args, start time.Time) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		elapsed := time.Since(start)
+		return rrse.E(rrse.Op("call"), rrse.TimeOut, &TimeoutError{
+			ServiceMethod: serviceMethod,
+			Elapsed:       elapsed,
+			StoreIds:      c.exceededStores(args, elapsed),
+			Err:           ctx.Err(),
+		})
+	}
+	return rrse.E(rrse.Op("call"), ctx.Err())
+}
+
+// CallRaw 使用任意类型的参数及返回值调用服务方法，不经过拦截器、重试及日志
+//
+// 用于 goridge 的 proto、raw 负载编码等参数不是 Args 的调用，正在重连时先等待重连完成，ctx 没有截止时间时直接返回 ErrNotReady。
+func (c *RpcClient) CallRaw(ctx context.Context, serviceMethod string, args any, reply any) error {
+	client, err := c.readyClient(ctx)
+	if err != nil {
+		return rrse.E(rrse.Op("call_raw"), rrse.Network, err)
+	}
+	call := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		if call.Error != nil {
+			if isConnError(call.Error) {
+				c.reconnect(client)
+			}
+			return rrse.E(rrse.Op("call_raw"), call.Error)
 		}
-		sanitizedArg.Store.Configuration = cfg
-		sanitizedArgs[i] = sanitizedArg
+		return nil
+	case <-ctx.Done():
+		return rrse.E(rrse.Op("call_raw"), ctx.Err())
 	}
-	loggerArgs := []any{"serviceMethod", serviceMethod, "args", sanitizedArgs, "reply", reply, "error", err}
+}
+
+// logCall 记录脱敏后的调用日志
+// Store.Configuration、Payload.Body、Result.Data 及 Result.Label、Result.Error 均经过 Redactor 脱敏，
+// 并按 Option.LogMaxItems、Option.LogMaxBytes 截断；成功的调用按 Option.LogSampleRate 采样
+func (c *RpcClient) logCall(serviceMethod string, args Args, reply *Reply, err error) {
+	level := slog.LevelInfo
 	if err != nil {
-		c.logger.Error("Call", loggerArgs...)
+		level = slog.LevelError
+	}
+	ctx := context.Background()
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+	if err == nil && !c.sampleLog() {
+		c.logStats.suppressed.Add(1)
+		return
+	}
+
+	sanitizedArgs := make([]Payload, len(args))
+	for i, arg := range args {
+		sanitizedArgs[i] = c.redactor.RedactPayload(arg)
+		sanitizedArgs[i].Body = c.limitLog(sanitizedArgs[i].Body)
+	}
+	loggerArgs := []any{"serviceMethod", serviceMethod, "args", sanitizedArgs}
+	if err != nil || !c.option.LogReplyOnError {
+		sanitizedReply := *reply
+		sanitizedReply.Results = make([]Result, len(reply.Results))
+		for i, result := range reply.Results {
+			result = c.redactor.RedactResult(result)
+			result.Data = c.limitLog(result.Data)
+			sanitizedReply.Results[i] = result
+		}
+		loggerArgs = append(loggerArgs, "reply", sanitizedReply)
 	} else {
-		c.logger.Info("Call", loggerArgs...)
+		loggerArgs = append(loggerArgs, "results", len(reply.Results))
 	}
-	return err
+	c.logger.Log(ctx, level, "Call", append(loggerArgs, "error", err)...)
 }
 
 // Close closes both the network connection and the RPC client.
 // It appends any errors encountered during the closing of the connection
 // or the client to the Error field. If the connection or client is nil,
-// it skips the closing operation for that component.
+// it skips the closing operation for that component. A pending reconnect
+// is stopped and the client moves to StateClosed.
 func (c *RpcClient) Close() error {
-	if c.Client == nil {
+	c.mu.Lock()
+	if c.state == StateClosed {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.state == StateConnecting {
+		close(c.ready)
+	}
+	c.state = StateClosed
+	if c.done != nil {
+		close(c.done)
+	}
+	client := c.client
+	c.mu.Unlock()
+
+	if client == nil {
 		return nil
 	}
 
-	if err := c.Client.Close(); err != nil {
+	if err := client.Close(); err != nil {
 		err = rrse.E(rrse.Op("close"), err)
 		c.logger.Error("Close", "error", err)
 		return err
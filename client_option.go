@@ -0,0 +1,196 @@
+package rpclient
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ClientOption 修改 Option 的函数，用于 New
+type ClientOption func(opt *Option)
+
+// New 使用 defaultOption 的深拷贝及 opts 创建客户端，不同客户端之间不会共享可变的配置
+//
+//	client, err := rpclient.New(addr,
+//		rpclient.WithCodec(rpclient.GoridgeCodec),
+//		rpclient.WithTimeout(30*time.Second),
+//	)
+func New(addr string, opts ...ClientOption) (*RpcClient, error) {
+	opt := defaultOption.clone()
+	for _, o := range opts {
+		o(opt)
+	}
+	return NewClient(addr, opt)
+}
+
+// WithOption 使用 opt 的副本替换此前的全部配置，用于在 Option 的基础上继续修改
+func WithOption(opt Option) ClientOption {
+	return func(o *Option) {
+		*o = *opt.clone()
+	}
+}
+
+// WithNetwork 设置网络类型：tcp、tcp4、tcp6、unix
+func WithNetwork(network string) ClientOption {
+	return func(o *Option) {
+		o.Network = network
+	}
+}
+
+// WithCodec 设置编解码器：json、goridge、msgpack
+func WithCodec(codec string) ClientOption {
+	return func(o *Option) {
+		o.Codec = codec
+	}
+}
+
+// WithGoridge 设置 goridge 编解码器配置
+func WithGoridge(goridge GoridgeOption) ClientOption {
+	return func(o *Option) {
+		o.Goridge = goridge
+	}
+}
+
+// WithLogLevel 设置日志级别：debug、info、warn、error
+func WithLogLevel(level string) ClientOption {
+	return func(o *Option) {
+		o.LogLevel = level
+	}
+}
+
+// WithLogger 设置自定义日志记录器
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *Option) {
+		o.Logger = logger
+	}
+}
+
+// WithLogHandler 设置自定义日志处理器
+func WithLogHandler(handler slog.Handler) ClientOption {
+	return func(o *Option) {
+		o.LogHandler = handler
+	}
+}
+
+// WithSensitiveWords 替换敏感词
+func WithSensitiveWords(words ...string) ClientOption {
+	return func(o *Option) {
+		o.SensitiveWords = slices.Clone(words)
+	}
+}
+
+// WithSensitiveDetectors 替换按值识别敏感数据的规则
+func WithSensitiveDetectors(detectors ...string) ClientOption {
+	return func(o *Option) {
+		o.SensitiveDetectors = slices.Clone(detectors)
+	}
+}
+
+// WithMaskStrategy 为匹配 key 的敏感键指定掩码方式
+func WithMaskStrategy(key, strategy string) ClientOption {
+	return func(o *Option) {
+		o.MaskStrategies = maps.Clone(o.MaskStrategies)
+		if o.MaskStrategies == nil {
+			o.MaskStrategies = make(map[string]string)
+		}
+		o.MaskStrategies[key] = strategy
+	}
+}
+
+// WithTimeout 设置店铺未设置超时时间时使用的默认值
+// Option 以秒为单位，不足 1 秒的部分向上取整，如 1500ms 按 2 秒计算；负数会在创建客户端时报错
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *Option) {
+		o.Timeout = ceilSeconds(timeout)
+	}
+}
+
+// WithDialTimeout 设置建立连接的超时时间
+// Option 以秒为单位，不足 1 秒的部分向上取整，如 100ms 按 1 秒计算；0 使用默认值 10 秒，任意负数表示不限制
+func WithDialTimeout(timeout time.Duration) ClientOption {
+	return func(o *Option) {
+		o.DialTimeout = ceilSeconds(timeout)
+	}
+}
+
+// WithKeepAlive 设置 TCP keepalive 探测间隔，0 使用系统默认值，任意负数表示禁用
+// Option 以秒为单位，不足 1 秒的部分向上取整，如 500ms 按 1 秒计算
+func WithKeepAlive(keepAlive time.Duration) ClientOption {
+	return func(o *Option) {
+		o.KeepAlive = ceilSeconds(keepAlive)
+	}
+}
+
+// WithDialContext 设置自定义建立连接的方式
+func WithDialContext(dial DialFunc) ClientOption {
+	return func(o *Option) {
+		o.DialContext = dial
+	}
+}
+
+// WithTLS 设置 TLS 传输配置并启用 TLS
+func WithTLS(tls TLSOption) ClientOption {
+	return func(o *Option) {
+		o.TLS = tls
+		o.TLS.Enabled = true
+	}
+}
+
+// WithCompression 设置压缩算法及压缩阈值（字节），threshold 为 0 时使用默认值 1024，负数表示全部压缩
+func WithCompression(algorithm string, threshold int) ClientOption {
+	return func(o *Option) {
+		o.Compression = algorithm
+		o.CompressionThreshold = threshold
+	}
+}
+
+// WithReconnect 设置断线重连配置
+func WithReconnect(reconnect ReconnectOption) ClientOption {
+	return func(o *Option) {
+		o.Reconnect = reconnect
+	}
+}
+
+// WithRetry 设置调用重试配置
+func WithRetry(retry RetryOption) ClientOption {
+	return func(o *Option) {
+		o.Retry = retry
+		o.Retry.Idempotent = slices.Clone(retry.Idempotent)
+	}
+}
+
+// WithCircuitBreaker 设置熔断配置并启用熔断
+func WithCircuitBreaker(circuitBreaker CircuitBreakerOption) ClientOption {
+	return func(o *Option) {
+		o.CircuitBreaker = circuitBreaker
+		o.CircuitBreaker.Enabled = true
+		o.CircuitBreaker.ServiceMethods = slices.Clone(circuitBreaker.ServiceMethods)
+	}
+}
+
+// WithIdempotent 追加可安全重试的服务方法
+func WithIdempotent(serviceMethods ...string) ClientOption {
+	return func(o *Option) {
+		o.Retry.Idempotent = append(slices.Clip(o.Retry.Idempotent), serviceMethods...)
+	}
+}
+
+// WithInterceptors 追加调用拦截器
+func WithInterceptors(interceptors ...UnaryInterceptor) ClientOption {
+	return func(o *Option) {
+		o.Interceptors = append(slices.Clip(o.Interceptors), interceptors...)
+	}
+}
+
+// ceilSeconds 将 d 转换为秒数，不足 1 秒的部分按 1 秒计算，任意负数均返回 -1
+func ceilSeconds(d time.Duration) int {
+	if d < 0 {
+		return -1
+	}
+	seconds := int(d / time.Second)
+	if d%time.Second > 0 {
+		seconds++
+	}
+	return seconds
+}
@@ -0,0 +1,81 @@
+package rpclient
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	ts := newTestServerCodec(t, serverCodecs[MsgpackCodec])
+	var buf bytes.Buffer
+	client, err := New(ts.addr,
+		WithCodec(MsgpackCodec),
+		WithLogHandler(slog.NewJSONHandler(&buf, nil)),
+		WithSensitiveWords("shop_token"),
+		WithMaskStrategy("shop_token", MaskFull),
+		WithTimeout(1500*time.Millisecond),
+		WithDialTimeout(time.Second),
+		WithIdempotent("Test.Echo"),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Close()
+
+	assert.Equal(t, MsgpackCodec, client.option.Codec)
+	assert.Equal(t, 2, client.option.Timeout)
+	assert.Equal(t, 1, client.option.DialTimeout)
+	assert.True(t, client.IsIdempotent("Test.Echo"))
+
+	var r Reply
+	args := NewArgs().Add(NewPayload(testStore("1"), map[string]any{"shop_token": "abcdefgh"}))
+	assert.NoError(t, client.Call("Test.Echo", args, &r))
+	assert.Contains(t, buf.String(), `"shop_token":"******"`)
+
+	_, err = New(ts.addr, WithCodec("xml"))
+	assert.ErrorContains(t, err, `unsupported codec "xml"`)
+}
+
+func TestNew_isolatesDefaults(t *testing.T) {
+	ts := newTestServer(t)
+	words := append([]string{}, defaultOption.SensitiveWords...)
+
+	a, err := New(ts.addr, WithLogLevel("error"))
+	assert.NoError(t, err)
+	defer a.Close()
+	b, err := NewClient(ts.addr, nil)
+	assert.NoError(t, err)
+	defer b.Close()
+
+	a.option.SensitiveWords[0] = "changed"
+	a.option.SensitiveWords = append(a.option.SensitiveWords, "appended")
+	assert.Equal(t, words, defaultOption.SensitiveWords)
+	assert.Equal(t, words, b.option.SensitiveWords)
+
+	base := Option{Codec: JsonCodec, SensitiveWords: []string{"token"}, LogLevel: "error"}
+	c, err := New(ts.addr, WithOption(base), WithIdempotent("Test.Echo"))
+	assert.NoError(t, err)
+	defer c.Close()
+	c.option.SensitiveWords[0] = "changed"
+	assert.Equal(t, []string{"token"}, base.SensitiveWords)
+	assert.Nil(t, base.Retry.Idempotent)
+}
+
+func TestCeilSeconds(t *testing.T) {
+	assert.Equal(t, 0, ceilSeconds(0))
+	assert.Equal(t, 1, ceilSeconds(time.Millisecond))
+	assert.Equal(t, 2, ceilSeconds(2*time.Second))
+	assert.Equal(t, 2, ceilSeconds(1500*time.Millisecond))
+	assert.Equal(t, -1, ceilSeconds(-time.Second))
+	assert.Equal(t, -1, ceilSeconds(-500*time.Millisecond))
+	assert.Equal(t, -1, ceilSeconds(-3*time.Second))
+
+	// 不足 1 秒的负数同样表示禁用 keepalive
+	var opt Option
+	WithKeepAlive(-time.Millisecond)(&opt)
+	assert.Equal(t, -1, opt.KeepAlive)
+}
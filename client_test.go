@@ -1,11 +1,25 @@
 package rpclient
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
+	rrse "github.com/roadrunner-server/errors"
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"github.com/spf13/cast"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
 )
 
 var (
@@ -47,12 +61,12 @@ func TestMain(m *testing.M) {
 			"static_file_server": cfg.StaticFileServer,
 		},
 	})
-	rpcClient, err = NewClient(cfg.RpcAddress, &Option{
-		Network:        "tcp",
-		Codec:          JsonCodec,
-		LogLevel:       "debug",
-		SensitiveWords: []string{"access_token", "app_key", "app_secret"},
-	})
+	opt, err := LoadOption("config.json")
+	if err != nil {
+		panic(err)
+	}
+	opt.SensitiveWords = []string{"access_token", "app_key", "app_secret"}
+	rpcClient, err = NewClient(cfg.RpcAddress, opt)
 	if err != nil {
 		panic(err)
 	}
@@ -152,3 +166,201 @@ func TestTemuSemiCustomizationInformation(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+// testService 本地测试用的 RPC 服务
+type testService struct {
+	mu    sync.Mutex
+	calls map[string]int // Flaky 中每个店铺的调用次数
+}
+
+// Echo 原样返回每个店铺的 Body，Configuration 中 fail 为 true 的店铺返回失败
+func (s *testService) Echo(args Args, reply *Reply) error {
+	reply.RequestId = fmt.Sprintf("echo-%d", time.Now().UnixNano())
+	for _, arg := range args {
+		result := Result{
+			StoreId:   arg.Store.ID,
+			StoreName: arg.Store.Name,
+			Ok:        true,
+			Data:      arg.Body,
+		}
+		if fail, _ := arg.Store.Configuration["fail"].(bool); fail {
+			result.Ok = false
+			result.Error = null.StringFrom("failed")
+		}
+		reply.Results = append(reply.Results, result)
+	}
+	return nil
+}
+
+// Flaky 每个店铺的前 Body 次调用返回失败
+func (s *testService) Flaky(args Args, reply *Reply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Echo(args, reply); err != nil {
+		return err
+	}
+	for i, arg := range args {
+		s.calls[arg.Store.ID]++
+		if s.calls[arg.Store.ID] <= cast.ToInt(arg.Body) {
+			reply.Results[i].Ok = false
+			reply.Results[i].Error = null.StringFrom("flaky")
+		}
+	}
+	return nil
+}
+
+// Sleep 等待 Body 指定的毫秒数后返回
+func (s *testService) Sleep(args Args, reply *Reply) error {
+	for _, arg := range args {
+		time.Sleep(time.Duration(cast.ToInt(arg.Body)) * time.Millisecond)
+	}
+	return s.Echo(args, reply)
+}
+
+// testServer 本地测试用的 RPC 服务端
+type testServer struct {
+	server   *rpc.Server
+	addr     string
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	return newTestServerCodec(t, serverCodecs[JsonCodec])
+}
+
+// newTestServerCodec 创建使用指定编解码器的测试服务端
+func newTestServerCodec(t *testing.T, newCodec func(conn io.ReadWriteCloser) rpc.ServerCodec) *testServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveTestServer(t, ln, newCodec)
+}
+
+// serveTestServer 在 ln 上启动测试服务端
+func serveTestServer(t *testing.T, ln net.Listener, newCodec func(conn io.ReadWriteCloser) rpc.ServerCodec) *testServer {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("Test", &testService{calls: make(map[string]int)}); err != nil {
+		t.Fatal(err)
+	}
+	ts := &testServer{server: server, addr: ln.Addr().String(), listener: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			ts.mu.Lock()
+			ts.conns = append(ts.conns, conn)
+			ts.mu.Unlock()
+			go server.ServeCodec(newCodec(conn))
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		ts.closeConns()
+	})
+	return ts
+}
+
+// serverCodecs 各编解码器对应的测试服务端编解码器
+var serverCodecs = map[string]func(conn io.ReadWriteCloser) rpc.ServerCodec{
+	JsonCodec: func(conn io.ReadWriteCloser) rpc.ServerCodec {
+		return jsonrpc.NewServerCodec(conn)
+	},
+	GoridgeCodec: func(conn io.ReadWriteCloser) rpc.ServerCodec {
+		return goridgeRpc.NewCodec(conn)
+	},
+	MsgpackCodec: NewMsgpackServerCodec,
+}
+
+// closeConns 断开所有已建立的连接
+func (ts *testServer) closeConns() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, conn := range ts.conns {
+		_ = conn.Close()
+	}
+	ts.conns = nil
+}
+
+// testOption 本地测试使用的配置，只记录错误日志
+var testOption = Option{Network: "tcp", Codec: JsonCodec, LogLevel: "error"}
+
+func newTestClient(t *testing.T, addr string, opt *Option) *RpcClient {
+	t.Helper()
+	if opt == nil {
+		opt = &testOption
+	}
+	client, err := NewClient(addr, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func testStore(id string) Store {
+	return Store{ID: id, Name: "Store " + id, Env: Prod, Configuration: Configuration{}}
+}
+
+func TestRpcClient_CallContext(t *testing.T) {
+	ts := newTestServer(t)
+	client := newTestClient(t, ts.addr, nil)
+
+	var r Reply
+	err := client.CallContext(context.Background(), "Test.Echo", NewArgs().Add(NewPayload(testStore("1"), "hello")), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.Results))
+	assert.Equal(t, "hello", r.Results[0].Data)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.CallContext(ctx, "Test.Sleep", NewArgs().Add(NewPayload(testStore("1"), 500)), &r)
+	assert.Error(t, err)
+	assert.True(t, rrse.Is(rrse.TimeOut, err))
+	te, ok := AsTimeoutError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "Test.Sleep", te.ServiceMethod)
+	assert.Equal(t, 0, len(r.Results))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = client.CallContext(ctx, "Test.Sleep", NewArgs().Add(NewPayload(testStore("1"), 500)), &r)
+	assert.Error(t, err)
+	assert.False(t, rrse.Is(rrse.TimeOut, err))
+
+	// 被放弃的调用不影响后续调用
+	err = client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("2"), "world")), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", r.Results[0].StoreId)
+}
+
+func TestNewClient_Logger(t *testing.T) {
+	ts := newTestServer(t)
+
+	var buf bytes.Buffer
+	opt := testOption
+	opt.LogHandler = slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	client := newTestClient(t, ts.addr, &opt)
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	assert.Contains(t, buf.String(), "msg=Call")
+	assert.Contains(t, buf.String(), "rpclient.dsn=tcp://"+ts.addr)
+	assert.Contains(t, buf.String(), "rpclient.codec=json")
+	assert.Contains(t, buf.String(), "rpclient.serviceMethod=Test.Echo")
+
+	buf.Reset()
+	opt = testOption
+	opt.Logger = slog.New(slog.NewJSONHandler(&buf, nil)).With("app", "test")
+	client = newTestClient(t, ts.addr, &opt)
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	assert.Contains(t, buf.String(), `"app":"test","rpclient":{"dsn":"tcp://`+ts.addr+`","codec":"json"`)
+}
@@ -0,0 +1,463 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/rpc"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+)
+
+const (
+	Random = "random" // 随机
+)
+
+// ClusterOption 多端点客户端配置
+type ClusterOption struct {
+	Strategy        string   `json:"strategy" yaml:"strategy" toml:"strategy"`                         // 端点选择策略：round_robin, random, least_pending
+	Backoff         Backoff  `json:"backoff" yaml:"backoff" toml:"backoff"`                            // 端点被标记为不可用后，再次尝试前的等待时间
+	ResolveInterval int      `json:"resolve_interval" yaml:"resolve_interval" toml:"resolve_interval"` // 调用 Resolver 刷新端点的间隔（秒）
+	Resolver        Resolver `json:"-" yaml:"-" toml:"-"`                                              // 动态获取端点，结果替换当前的端点列表
+}
+
+var defaultClusterOption = ClusterOption{
+	Strategy:        RoundRobin,
+	Backoff:         defaultBackoff,
+	ResolveInterval: 30,
+}
+
+// EndpointStatus 端点状态
+type EndpointStatus struct {
+	Addr      string    `json:"addr"`
+	Healthy   bool      `json:"healthy"`
+	InFlight  int64     `json:"in_flight"`  // 未完成的调用数
+	Failures  int       `json:"failures"`   // 连续失败次数
+	LastError string    `json:"last_error"` // 最近一次导致端点不可用的错误
+	RetryAt   time.Time `json:"retry_at"`   // 不可用的端点在该时间之后才会被再次尝试
+}
+
+// endpoint 集群中的一个端点
+type endpoint struct {
+	addr     string
+	inFlight atomic.Int64
+
+	dialMu sync.Mutex // 保证同一时间只有一个调用在建立连接
+
+	// 以下字段由 ClusterClient.mu 保护
+	client    *RpcClient
+	failures  int
+	lastError error
+	retryAt   time.Time
+	removed   bool
+}
+
+// ClusterClient 多端点客户端
+//
+// 按 ClusterOption.Strategy 在健康的端点之间分配调用。端点连接失败或连接断开时被标记为不可用，
+// 按 ClusterOption.Backoff 等待后再次尝试，调用会被转移到其他端点：
+// 请求发出前失败（如建立连接失败）的调用总是转移，请求发出后连接断开的调用只有幂等的服务方法才会转移。
+type ClusterClient struct {
+	option        *Option
+	clusterOption *ClusterOption
+	logger        *slog.Logger
+	breaker       *CircuitBreaker // 所有端点共享的熔断器，为 nil 时不熔断
+
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	next      int
+	closed    bool
+	done      chan struct{}
+}
+
+// NewClusterClient creates a client that balances calls across endpoints.
+//
+// The opt parameter is passed to NewClient for every endpoint. If clusterOpt
+// is nil, the defaultClusterOption is used. When ClusterOption.Resolver is set,
+// its result replaces endpoints at start-up and every ResolveInterval seconds,
+// or as soon as a Resolver that implements Watcher reports a change.
+// Every endpoint is dialed up front; an error is returned only when none of
+// them is reachable.
+func NewClusterClient(endpoints []string, opt *Option, clusterOpt *ClusterOption) (*ClusterClient, error) {
+	const op = rrse.Op("new_cluster_client")
+	if opt == nil {
+		opt = &defaultOption
+	}
+	if err := opt.Validate(); err != nil {
+		return nil, rrse.E(op, err)
+	}
+	if clusterOpt == nil {
+		clusterOpt = &defaultClusterOption
+	}
+	clusterOption := *clusterOpt
+	if !slices.Contains([]string{"", RoundRobin, Random, LeastPending}, clusterOption.Strategy) {
+		return nil, rrse.E(op, fmt.Errorf("rpclient: unsupported cluster strategy %q", clusterOption.Strategy))
+	}
+	if clusterOption.ResolveInterval <= 0 {
+		clusterOption.ResolveInterval = defaultClusterOption.ResolveInterval
+	}
+
+	c := &ClusterClient{
+		option:        opt.withDefaults(),
+		clusterOption: &clusterOption,
+		done:          make(chan struct{}),
+	}
+	if clusterOption.Resolver != nil {
+		resolved, err := clusterOption.Resolver.Resolve(context.Background())
+		if err != nil {
+			return nil, rrse.E(op, err)
+		}
+		endpoints = resolved
+	}
+	if len(endpoints) == 0 {
+		return nil, rrse.E(op, ErrNoEndpoint)
+	}
+	c.logger = newLogger(strings.Join(endpoints, ","), c.option).With("cluster", true)
+	if c.option.CircuitBreaker.Enabled {
+		c.breaker = newCircuitBreaker(c.option.CircuitBreaker, c.logger)
+	}
+	c.SetEndpoints(endpoints)
+
+	var errs []error
+	for _, ep := range c.endpoints {
+		if _, err := c.connect(context.Background(), ep); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(c.endpoints) {
+		_ = c.Close()
+		return nil, rrse.E(op, errors.Join(errs...))
+	}
+	if clusterOption.Resolver != nil {
+		go c.resolveLoop()
+	}
+	return c, nil
+}
+
+// SetEndpoints 替换端点列表，新增的端点在首次使用时建立连接，移除的端点在未完成的调用结束后关闭
+func (c *ClusterClient) SetEndpoints(addrs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+
+	endpoints := make([]*endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		if slices.ContainsFunc(endpoints, func(ep *endpoint) bool { return ep.addr == addr }) {
+			continue
+		}
+		i := slices.IndexFunc(c.endpoints, func(ep *endpoint) bool { return ep.addr == addr })
+		if i >= 0 {
+			endpoints = append(endpoints, c.endpoints[i])
+		} else {
+			endpoints = append(endpoints, &endpoint{addr: addr})
+			c.logger.Info("Endpoint", "addr", addr, "action", "add")
+		}
+	}
+	for _, ep := range c.endpoints {
+		if slices.Contains(endpoints, ep) {
+			continue
+		}
+		ep.removed = true
+		c.logger.Info("Endpoint", "addr", ep.addr, "action", "remove")
+		if ep.inFlight.Load() == 0 {
+			c.closeEndpoint(ep)
+		}
+	}
+	c.endpoints = endpoints
+}
+
+// Endpoints 返回所有端点的状态
+func (c *ClusterClient) Endpoints() []EndpointStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	statuses := make([]EndpointStatus, 0, len(c.endpoints))
+	for _, ep := range c.endpoints {
+		status := EndpointStatus{
+			Addr:     ep.addr,
+			Healthy:  ep.failures == 0,
+			InFlight: ep.inFlight.Load(),
+			Failures: ep.failures,
+			RetryAt:  ep.retryAt,
+		}
+		if ep.lastError != nil {
+			status.LastError = ep.lastError.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// pick 按策略选择一个未尝试过的可用端点，没有可用端点时返回 nil
+func (c *ClusterClient) pick(tried []*endpoint) *endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	now := time.Now()
+	var candidates []*endpoint
+	for _, ep := range c.endpoints {
+		if !slices.Contains(tried, ep) && (ep.failures == 0 || !now.Before(ep.retryAt)) {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var ep *endpoint
+	switch c.clusterOption.Strategy {
+	case Random:
+		ep = candidates[rand.IntN(len(candidates))]
+	case LeastPending:
+		ep = candidates[0]
+		for _, candidate := range candidates[1:] {
+			if candidate.inFlight.Load() < ep.inFlight.Load() {
+				ep = candidate
+			}
+		}
+	default:
+		ep = candidates[c.next%len(candidates)]
+		c.next++
+	}
+	ep.inFlight.Add(1)
+	return ep
+}
+
+// release 结束一次调用，已移除的端点在没有未完成的调用后关闭
+func (c *ClusterClient) release(ep *endpoint) {
+	if ep.inFlight.Add(-1) > 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ep.removed {
+		c.closeEndpoint(ep)
+	}
+}
+
+// connect 返回端点的连接，尚未建立或已断开时重新建立连接，建立连接同时受 ctx 及 Option.DialTimeout 限制
+func (c *ClusterClient) connect(ctx context.Context, ep *endpoint) (*RpcClient, error) {
+	ep.dialMu.Lock()
+	defer ep.dialMu.Unlock()
+
+	c.mu.RLock()
+	client := ep.client
+	c.mu.RUnlock()
+	if client != nil && client.State() == StateReady {
+		return client, nil
+	}
+
+	// 断开的连接由集群客户端转移调用，不需要自动重连及重试；熔断由集群客户端统一处理
+	opt := *c.option
+	opt.Reconnect.Disabled = true
+	opt.Retry.MaxAttempts = 1
+	opt.CircuitBreaker.Enabled = false
+	client, err := newClient(ctx, ep.addr, &opt)
+	if err != nil {
+		// 调用方取消或超时不代表端点不可用
+		if ctx.Err() == nil {
+			c.markUnhealthy(ep, nil, err)
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || ep.removed {
+		_ = client.Close()
+		return nil, rrse.E(rrse.Op("cluster_connect"), ErrClosed)
+	}
+	if ep.client != nil {
+		_ = ep.client.Close()
+	}
+	ep.client = client
+	return client, nil
+}
+
+// markUnhealthy 将端点标记为不可用并关闭其连接
+func (c *ClusterClient) markUnhealthy(ep *endpoint, client *RpcClient, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ep.retryAt = time.Now().Add(c.clusterOption.Backoff.Duration(ep.failures))
+	ep.failures++
+	ep.lastError = err
+	if client != nil && ep.client == client {
+		_ = client.Close()
+		ep.client = nil
+	}
+	c.logger.Warn("Endpoint", "addr", ep.addr, "action", "unhealthy", "failures", ep.failures, "error", err)
+}
+
+// markHealthy 将端点标记为可用
+func (c *ClusterClient) markHealthy(ep *endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ep.failures > 0 {
+		c.logger.Info("Endpoint", "addr", ep.addr, "action", "healthy")
+	}
+	ep.failures = 0
+	ep.lastError = nil
+	ep.retryAt = time.Time{}
+}
+
+// closeEndpoint 关闭端点的连接，调用方需持有 c.mu
+func (c *ClusterClient) closeEndpoint(ep *endpoint) {
+	if ep.client != nil {
+		_ = ep.client.Close()
+		ep.client = nil
+	}
+}
+
+func (c *ClusterClient) resolveLoop() {
+	ticker := time.NewTicker(time.Duration(c.clusterOption.ResolveInterval) * time.Second)
+	defer ticker.Stop()
+
+	var changes <-chan struct{}
+	if watcher, ok := c.clusterOption.Resolver.(Watcher); ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes = watcher.Watch(ctx)
+	}
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.refresh()
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+			c.refresh()
+		}
+	}
+}
+
+// refresh 调用 Resolver 刷新端点，失败或返回空列表时保留当前端点
+func (c *ClusterClient) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.clusterOption.ResolveInterval)*time.Second)
+	defer cancel()
+	addrs, err := c.clusterOption.Resolver.Resolve(ctx)
+	if err != nil || len(addrs) == 0 {
+		c.logger.Warn("Resolve", "endpoints", addrs, "error", err)
+		return
+	}
+	c.SetEndpoints(addrs)
+}
+
+// Call calls the RPC server on one of the healthy endpoints.
+func (c *ClusterClient) Call(serviceMethod string, args Args, reply *Reply) error {
+	return c.CallContext(context.Background(), serviceMethod, args, reply)
+}
+
+// CallContext is like Call but honours the cancellation and deadline of ctx.
+//
+// A call whose endpoint cannot be connected is moved to another endpoint. A
+// call whose connection breaks after the request was sent is moved only when
+// serviceMethod matches Option.Retry.Idempotent.
+//
+// When Option.CircuitBreaker is enabled, the circuit breaker is shared by every
+// endpoint and counts the outcome after failover.
+func (c *ClusterClient) CallContext(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	if c.breaker != nil {
+		return c.breaker.Intercept(ctx, serviceMethod, args, reply, c.invoke)
+	}
+	return c.invoke(ctx, serviceMethod, args, reply)
+}
+
+// invoke 在可用的端点上发起调用，失败时按规则转移到其他端点
+func (c *ClusterClient) invoke(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	var tried []*endpoint
+	var lastErr error
+	for {
+		// ctx 结束后不再转移到其他端点
+		if err := ctx.Err(); err != nil {
+			reply.Reset()
+			return opContextError(rrse.Op("cluster_call"), err)
+		}
+		ep := c.pick(tried)
+		if ep == nil {
+			break
+		}
+		tried = append(tried, ep)
+
+		client, err := c.connect(ctx, ep)
+		if err != nil {
+			c.release(ep)
+			lastErr = err
+			continue
+		}
+		err = client.CallContext(ctx, serviceMethod, args, reply)
+		c.release(ep)
+		switch {
+		case err == nil || !isEndpointError(err):
+			c.markHealthy(ep)
+			return err
+		case ctx.Err() != nil:
+			return err
+		}
+
+		c.markUnhealthy(ep, client, err)
+		lastErr = err
+		if !isNotSent(err) && !matchServiceMethod(c.option.Retry.Idempotent, serviceMethod) {
+			return err
+		}
+		c.logger.Warn("Failover", "serviceMethod", serviceMethod, "addr", ep.addr, "error", err)
+	}
+
+	reply.Reset()
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return rrse.E(rrse.Op("cluster_call"), ErrClusterClosed)
+	}
+	if lastErr != nil {
+		return rrse.E(rrse.Op("cluster_call"), rrse.Network, lastErr)
+	}
+	return rrse.E(rrse.Op("cluster_call"), rrse.Network, ErrNoEndpoint)
+}
+
+// Close closes the connections to every endpoint. Calls made after Close
+// return ErrClusterClosed.
+func (c *ClusterClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	for _, ep := range c.endpoints {
+		c.closeEndpoint(ep)
+	}
+	return nil
+}
+
+// isEndpointError 判断错误是否由端点不可用导致
+func isEndpointError(err error) bool {
+	if isConnError(err) || isNotSent(err) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(cause(err), &opErr)
+}
+
+// isNotSent 判断调用是否在请求发出前失败
+// net/rpc 在连接已断开时不发送请求，直接返回 rpc.ErrShutdown
+func isNotSent(err error) bool {
+	err = cause(err)
+	return errors.Is(err, ErrNotReady) || errors.Is(err, ErrClosed) || errors.Is(err, rpc.ErrShutdown)
+}
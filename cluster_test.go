@@ -0,0 +1,239 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nodeService 返回所在测试服务端的地址，用于区分调用落在哪个端点
+type nodeService struct {
+	addr string
+}
+
+func (s *nodeService) Addr(args Args, reply *Reply) error {
+	reply.RequestId = s.addr
+	return nil
+}
+
+func newNodeTestServer(t *testing.T) *testServer {
+	ts := newTestServer(t)
+	if err := ts.server.RegisterName("Node", &nodeService{addr: ts.addr}); err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+// stop 关闭测试服务端的监听及已建立的连接
+func (ts *testServer) stop() {
+	_ = ts.listener.Close()
+	ts.closeConns()
+}
+
+// unusedAddr 返回一个没有服务监听的地址
+func unusedAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func newTestCluster(t *testing.T, endpoints []string, clusterOpt *ClusterOption) *ClusterClient {
+	t.Helper()
+	opt := testOption
+	opt.Retry.Idempotent = []string{"Node.*"}
+	c, err := NewClusterClient(endpoints, &opt, clusterOpt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+func callNode(t *testing.T, c *ClusterClient, serviceMethod string) string {
+	t.Helper()
+	var r Reply
+	if err := c.Call(serviceMethod, NewArgs(), &r); err != nil {
+		t.Fatal(err)
+	}
+	return r.RequestId
+}
+
+func TestClusterClient_Strategy(t *testing.T) {
+	a, b := newNodeTestServer(t), newNodeTestServer(t)
+
+	c := newTestCluster(t, []string{a.addr, b.addr}, nil)
+	var addrs []string
+	for i := 0; i < 4; i++ {
+		addrs = append(addrs, callNode(t, c, "Node.Addr"))
+	}
+	assert.Equal(t, []string{a.addr, b.addr, a.addr, b.addr}, addrs)
+
+	c = newTestCluster(t, []string{a.addr, b.addr}, &ClusterOption{Strategy: Random})
+	seen := make(map[string]int)
+	for i := 0; i < 50; i++ {
+		seen[callNode(t, c, "Node.Addr")]++
+	}
+	assert.Len(t, seen, 2)
+
+	// least_pending 避开有未完成调用的端点
+	c = newTestCluster(t, []string{a.addr, b.addr}, &ClusterOption{Strategy: LeastPending})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var r Reply
+		assert.NoError(t, c.Call("Test.Sleep", NewArgs().Add(NewPayload(testStore("1"), 300)), &r))
+	}()
+	time.Sleep(100 * time.Millisecond)
+	busy := a.addr
+	for _, status := range c.Endpoints() {
+		if status.InFlight > 0 {
+			busy = status.Addr
+		}
+	}
+	for i := 0; i < 3; i++ {
+		assert.NotEqual(t, busy, callNode(t, c, "Node.Addr"))
+	}
+	wg.Wait()
+
+	_, err := NewClusterClient([]string{a.addr}, &testOption, &ClusterOption{Strategy: "weighted"})
+	assert.ErrorContains(t, err, `unsupported cluster strategy "weighted"`)
+}
+
+func TestClusterClient_Failover(t *testing.T) {
+	a, b := newNodeTestServer(t), newNodeTestServer(t)
+	down := unusedAddr(t)
+
+	c := newTestCluster(t, []string{down, a.addr, b.addr}, &ClusterOption{Backoff: Backoff{Initial: 60000, Max: 60000}})
+	statuses := c.Endpoints()
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, 1, statuses[0].Failures)
+	assert.NotEmpty(t, statuses[0].LastError)
+	assert.True(t, statuses[1].Healthy)
+
+	// 不可用的端点在等待时间内不会被选中
+	for i := 0; i < 4; i++ {
+		assert.NotEqual(t, down, callNode(t, c, "Node.Addr"))
+	}
+
+	// 端点断开后调用转移到其他端点
+	a.stop()
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, b.addr, callNode(t, c, "Node.Addr"))
+	}
+	for _, status := range c.Endpoints() {
+		assert.Equal(t, status.Addr == b.addr, status.Healthy, status.Addr)
+	}
+
+	b.stop()
+	var r Reply
+	err := c.Call("Node.Addr", NewArgs(), &r)
+	assert.Error(t, err)
+	err = c.Call("Node.Addr", NewArgs(), &r)
+	assert.True(t, errors.Is(cause(err), ErrNoEndpoint), err)
+
+	assert.NoError(t, c.Close())
+	err = c.Call("Node.Addr", NewArgs(), &r)
+	assert.True(t, errors.Is(cause(err), ErrClusterClosed), err)
+
+	_, err = NewClusterClient([]string{down}, &testOption, nil)
+	assert.Error(t, err)
+	_, err = NewClusterClient(nil, &testOption, nil)
+	assert.True(t, errors.Is(cause(err), ErrNoEndpoint), err)
+}
+
+func TestClusterClient_FailoverContext(t *testing.T) {
+	a := newNodeTestServer(t)
+	slow1, slow2 := unusedAddr(t), unusedAddr(t)
+
+	// 模拟无响应的主机：hang 之后建立连接一直阻塞到 ctx 结束
+	var hang atomic.Bool
+	opt := testOption
+	opt.DialTimeout = -1
+	opt.Retry.Idempotent = []string{"Node.*"}
+	opt.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if hang.Load() {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	c, err := NewClusterClient([]string{a.addr, slow1, slow2}, &opt, &ClusterOption{Backoff: Backoff{Initial: 10, Max: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	a.stop()
+	hang.Store(true)
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var r Reply
+	err = c.CallContext(ctx, "Node.Addr", NewArgs(), &r)
+	assert.True(t, errors.Is(cause(err), context.DeadlineExceeded), err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClusterClient_Recover(t *testing.T) {
+	a := newNodeTestServer(t)
+	addr := unusedAddr(t)
+	c := newTestCluster(t, []string{a.addr, addr}, &ClusterOption{Backoff: Backoff{Initial: 50, Max: 50, Jitter: 0.01}})
+	assert.False(t, c.Endpoints()[1].Healthy)
+
+	// 端点恢复后在等待时间结束时重新使用
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	b := serveTestServer(t, ln, serverCodecs[JsonCodec])
+	if err = b.server.RegisterName("Node", &nodeService{addr: addr}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		seen[callNode(t, c, "Node.Addr")] = true
+	}
+	assert.True(t, seen[addr])
+	assert.True(t, c.Endpoints()[1].Healthy)
+}
+
+func TestClusterClient_Resolve(t *testing.T) {
+	a, b := newNodeTestServer(t), newNodeTestServer(t)
+	var mu sync.Mutex
+	endpoints := []string{a.addr}
+	c := newTestCluster(t, nil, &ClusterOption{
+		ResolveInterval: 1,
+		Resolver: ResolveFunc(func(ctx context.Context) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return endpoints, nil
+		}),
+	})
+	assert.Equal(t, a.addr, callNode(t, c, "Node.Addr"))
+
+	mu.Lock()
+	endpoints = []string{b.addr}
+	mu.Unlock()
+	assert.Eventually(t, func() bool {
+		statuses := c.Endpoints()
+		return len(statuses) == 1 && statuses[0].Addr == b.addr
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, b.addr, callNode(t, c, "Node.Addr"))
+}
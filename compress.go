@@ -0,0 +1,229 @@
+package rpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	GzipCompression = "gzip"
+)
+
+// 压缩握手及帧格式
+//
+// 建立连接后客户端发送 4 字节握手 `RPZ` + 算法，服务端以相同格式回复协商的算法（0 表示不压缩）。
+// 此后双方每次写入的数据封装为一帧：1 字节标志（0 未压缩、1 gzip）+ 4 字节大端序长度 + 数据。
+// 编解码器（json、goridge、msgpack）不感知压缩，可以与任意编解码器组合使用。
+const (
+	compressMagic = "RPZ"
+
+	compressNone byte = 0
+	compressGzip byte = 1
+
+	compressHeaderSize = 5
+	compressMaxFrame   = 256 << 20 // 单帧最大字节数，超出视为数据损坏
+
+	// compressHandshakeTimeout 等待服务端回复握手的最长时间，未实现压缩握手的服务端不会回复
+	compressHandshakeTimeout = 3 * time.Second
+)
+
+// compressAlgorithms 压缩算法名称 => 握手及帧中的标志
+var compressAlgorithms = map[string]byte{
+	GzipCompression: compressGzip,
+}
+
+// compressConn 按帧压缩读写数据的连接
+type compressConn struct {
+	net.Conn
+	algorithm byte
+	threshold int
+
+	wmu  sync.Mutex
+	wbuf bytes.Buffer
+	gw   *gzip.Writer
+
+	rmu  sync.Mutex
+	rbuf bytes.Reader
+	gr   *gzip.Reader
+}
+
+// newCompressConn 与服务端协商压缩算法并返回压缩连接，服务端不支持该算法时仍使用帧格式但不压缩
+// 超过 threshold 字节的帧才会被压缩；服务端必须实现压缩握手，回复不是握手格式时返回错误，
+// 调用方需要为 conn 设置读取截止时间，避免未实现握手的服务端不回复时一直等待
+func newCompressConn(conn net.Conn, algorithm string, threshold int) (net.Conn, error) {
+	flag, ok := compressAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("rpclient: unknown compression %q", algorithm)
+	}
+	if _, err := conn.Write(append([]byte(compressMagic), flag)); err != nil {
+		return nil, err
+	}
+	// 逐段读取回复，首字节不是握手魔数时立即失败，不等待后续字节
+	ack := make([]byte, len(compressMagic)+1)
+	if _, err := io.ReadFull(conn, ack[:1]); err != nil {
+		return nil, compressHandshakeError(err)
+	}
+	if ack[0] != compressMagic[0] {
+		return nil, fmt.Errorf("rpclient: compression handshake: unexpected reply %q, the server does not support compression", ack[:1])
+	}
+	if _, err := io.ReadFull(conn, ack[1:]); err != nil {
+		return nil, compressHandshakeError(err)
+	}
+	if string(ack[:len(compressMagic)]) != compressMagic {
+		return nil, fmt.Errorf("rpclient: compression handshake: unexpected reply %q, the server does not support compression", ack)
+	}
+	if ack[len(compressMagic)] != flag {
+		flag = compressNone
+	}
+	return &compressConn{Conn: conn, algorithm: flag, threshold: threshold}, nil
+}
+
+// AcceptCompression 在服务端完成压缩握手并返回压缩连接，标准的 net/rpc、goridge 服务端用它包装 net.Conn 后即可接受开启压缩的客户端：
+//
+//	cc, err := rpclient.AcceptCompression(conn, 1024)
+//	if err != nil {
+//		conn.Close()
+//		return
+//	}
+//	server.ServeCodec(jsonrpc.NewServerCodec(cc))
+//
+// 支持客户端请求的算法时使用该算法，否则回复 0 不压缩；超过 threshold 字节的响应才压缩，负数表示全部压缩。
+// 握手最多等待 compressHandshakeTimeout，完成后清除 conn 的读取截止时间；收到的不是压缩握手时返回错误。
+func AcceptCompression(conn net.Conn, threshold int) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(compressHandshakeTimeout)); err != nil {
+		return nil, err
+	}
+	hello := make([]byte, len(compressMagic)+1)
+	if _, err := io.ReadFull(conn, hello); err != nil {
+		return nil, fmt.Errorf("rpclient: compression handshake: %w", err)
+	}
+	if string(hello[:len(compressMagic)]) != compressMagic {
+		return nil, fmt.Errorf("rpclient: compression handshake: unexpected hello %q, the client did not enable compression", hello)
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	flag := hello[len(compressMagic)]
+	if !slices.Contains(slices.Collect(maps.Values(compressAlgorithms)), flag) {
+		flag = compressNone
+	}
+	if _, err := conn.Write(append([]byte(compressMagic), flag)); err != nil {
+		return nil, err
+	}
+	return &compressConn{Conn: conn, algorithm: flag, threshold: threshold}, nil
+}
+
+// compressHandshakeError 握手回复读取失败，超时或连接被关闭通常是因为服务端未实现压缩握手
+func compressHandshakeError(err error) error {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("rpclient: compression handshake: %w (the server may not support compression)", err)
+	}
+	return fmt.Errorf("rpclient: compression handshake: %w", err)
+}
+
+func (c *compressConn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.wbuf.Reset()
+	c.wbuf.Write(make([]byte, compressHeaderSize))
+	flag := compressNone
+	if c.algorithm == compressGzip && len(p) > c.threshold {
+		if c.gw == nil {
+			c.gw = gzip.NewWriter(&c.wbuf)
+		} else {
+			c.gw.Reset(&c.wbuf)
+		}
+		if _, err := c.gw.Write(p); err != nil {
+			return 0, err
+		}
+		if err := c.gw.Close(); err != nil {
+			return 0, err
+		}
+		flag = compressGzip
+		// 压缩后反而更大时发送原始数据
+		if c.wbuf.Len()-compressHeaderSize >= len(p) {
+			c.wbuf.Truncate(compressHeaderSize)
+			flag = compressNone
+		}
+	}
+	if flag == compressNone {
+		c.wbuf.Write(p)
+	}
+
+	frame := c.wbuf.Bytes()
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:compressHeaderSize], uint32(len(frame)-compressHeaderSize))
+	if _, err := c.Conn.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *compressConn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for c.rbuf.Len() == 0 {
+		if err := c.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+	return c.rbuf.Read(p)
+}
+
+// readFrame 读取下一帧并解压到 rbuf
+func (c *compressConn) readFrame() error {
+	var header [compressHeaderSize]byte
+	if _, err := io.ReadFull(c.Conn, header[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > compressMaxFrame {
+		return fmt.Errorf("rpclient: compressed frame too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.Conn, data); err != nil {
+		return noEOF(err)
+	}
+
+	switch header[0] {
+	case compressNone:
+	case compressGzip:
+		var err error
+		if c.gr == nil {
+			c.gr, err = gzip.NewReader(bytes.NewReader(data))
+		} else {
+			err = c.gr.Reset(bytes.NewReader(data))
+		}
+		if err != nil {
+			return fmt.Errorf("rpclient: invalid compressed frame: %w", err)
+		}
+		if data, err = io.ReadAll(c.gr); err != nil {
+			return fmt.Errorf("rpclient: invalid compressed frame: %w", err)
+		}
+	default:
+		return fmt.Errorf("rpclient: unknown frame flag %d", header[0])
+	}
+	c.rbuf.Reset(data)
+	return nil
+}
+
+// noEOF 帧读取到一半时连接断开视为 io.ErrUnexpectedEOF
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
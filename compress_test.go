@@ -0,0 +1,171 @@
+package rpclient
+
+import (
+	"io"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingConn 统计从连接读取的原始字节数
+type countingConn struct {
+	net.Conn
+	n *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// rejectCompress 模拟不支持客户端所请求算法的服务端，回复不压缩
+func rejectCompress(t *testing.T, conn net.Conn) net.Conn {
+	hello := make([]byte, len(compressMagic)+1)
+	if _, err := io.ReadFull(conn, hello); err != nil {
+		t.Error(err)
+		return conn
+	}
+	if _, err := conn.Write(append([]byte(compressMagic), compressNone)); err != nil {
+		t.Error(err)
+	}
+	return &compressConn{Conn: conn, algorithm: compressNone}
+}
+
+func TestRpcClient_Compression(t *testing.T) {
+	body := strings.Repeat("order-", 20000)
+
+	for codec, newCodec := range serverCodecs {
+		for _, supported := range []bool{true, false} {
+			var received atomic.Int64
+			ts := newTestServerCodec(t, func(conn io.ReadWriteCloser) rpc.ServerCodec {
+				counted := countingConn{Conn: conn.(net.Conn), n: &received}
+				if !supported {
+					return newCodec(rejectCompress(t, counted))
+				}
+				cc, err := AcceptCompression(counted, 64)
+				if !assert.NoError(t, err) {
+					return newCodec(conn)
+				}
+				return newCodec(cc)
+			})
+			opt := testOption
+			opt.Codec = codec
+			opt.Compression = GzipCompression
+			opt.CompressionThreshold = 1024
+			client := newTestClient(t, ts.addr, &opt)
+
+			var r Reply
+			assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), body)), &r), codec)
+			assert.Len(t, r.Results, 1, codec)
+			assert.Equal(t, body, r.Results[0].Data, codec)
+			if supported {
+				assert.Less(t, received.Load(), int64(len(body)/10), codec)
+			} else {
+				assert.Greater(t, received.Load(), int64(len(body)), codec)
+			}
+
+			// 低于阈值的请求不压缩
+			assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("2"), "small")), &r), codec)
+			assert.Equal(t, "small", r.Results[0].Data, codec)
+		}
+	}
+}
+
+func TestCompressConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		sc, err := AcceptCompression(server, 0)
+		if !assert.NoError(t, err) {
+			return
+		}
+		b := make([]byte, 3)
+		for {
+			if _, err := io.ReadFull(sc, b); err != nil {
+				return
+			}
+			if _, err := sc.Write(b); err != nil {
+				return
+			}
+		}
+	}()
+
+	cc, err := newCompressConn(client, GzipCompression, 0)
+	assert.NoError(t, err)
+	_, err = cc.Write([]byte("abcdef"))
+	assert.NoError(t, err)
+	b := make([]byte, 6)
+	_, err = io.ReadFull(cc, b)
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef", string(b))
+
+	_, err = newCompressConn(client, "zstd", 0)
+	assert.ErrorContains(t, err, `unknown compression "zstd"`)
+}
+
+func TestRpcClient_CompressionStockServer(t *testing.T) {
+	// 未使用压缩连接包装的服务端不回复握手或直接关闭连接，客户端不能一直等待
+	for codec, newCodec := range serverCodecs {
+		ts := newTestServerCodec(t, func(conn io.ReadWriteCloser) rpc.ServerCodec {
+			return newCodec(conn)
+		})
+		opt := testOption
+		opt.Codec = codec
+		opt.Compression = GzipCompression
+		opt.DialTimeout = -1
+		start := time.Now()
+		_, err := NewClient(ts.addr, &opt)
+		assert.ErrorContains(t, err, "compression handshake", codec)
+		assert.Less(t, time.Since(start), compressHandshakeTimeout+2*time.Second, codec)
+	}
+
+	// 回复不是握手格式时立即失败
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		hello := make([]byte, len(compressMagic)+1)
+		_, _ = io.ReadFull(server, hello)
+		_, _ = server.Write([]byte(`{"id":0}`))
+	}()
+	_ = client.SetReadDeadline(time.Now().Add(compressHandshakeTimeout))
+	start := time.Now()
+	_, err := newCompressConn(client, GzipCompression, 0)
+	assert.ErrorContains(t, err, "does not support compression")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAcceptCompression(t *testing.T) {
+	// 未开启压缩的客户端直接发送请求
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		_, _ = client.Write([]byte(`{"method":"Test.Echo"}`))
+	}()
+	_, err := AcceptCompression(server, 0)
+	assert.ErrorContains(t, err, "the client did not enable compression")
+	_ = server.Close()
+
+	// 不支持的算法回复不压缩
+	client, server = net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go func() {
+		_, _ = client.Write(append([]byte(compressMagic), 9))
+	}()
+	go func() {
+		_, _ = AcceptCompression(server, 0)
+	}()
+	ack := make([]byte, len(compressMagic)+1)
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(client, ack)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte(compressMagic), compressNone), ack)
+}
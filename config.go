@@ -0,0 +1,155 @@
+package rpclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOption 从配置文件中读取 Option，按扩展名识别格式：.json、.yaml、.yml、.toml
+//
+// 文件中未设置的字段使用 defaultOption 中的值，读取后会调用 Validate 检查配置。
+// 文件可以包含 Option 以外的配置项，这些配置项会被忽略。
+func LoadOption(path string) (*Option, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rpclient: read option file: %w", err)
+	}
+
+	opt := defaultOption.clone()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(b, opt)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, opt)
+	case ".toml":
+		err = toml.Unmarshal(b, opt)
+	default:
+		return nil, fmt.Errorf("rpclient: unsupported option file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rpclient: parse option file %s: %w", path, err)
+	}
+	if err = opt.Validate(); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// OptionFromEnv 从环境变量中读取 Option，未设置的字段使用 defaultOption 中的值，规则见 Option.LoadEnv
+func OptionFromEnv(prefix string) (*Option, error) {
+	opt := defaultOption.clone()
+	if err := opt.LoadEnv(prefix); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// LoadEnv 使用环境变量覆盖 Option 中的字段，覆盖后会调用 Validate 检查配置
+//
+// 环境变量名为 prefix 加上大写的 `json` 标签，嵌套的字段以 `_` 连接，如 prefix 为 RPCLIENT 时：
+//
+//	RPCLIENT_CODEC=goridge
+//	RPCLIENT_LOG_LEVEL=info
+//	RPCLIENT_RETRY_MAX_ATTEMPTS=5
+//	RPCLIENT_TLS_CA_FILE=/etc/rpc/ca.pem
+//	RPCLIENT_SENSITIVE_WORDS=token,secret      // []string 以逗号分隔
+//	RPCLIENT_MASK_STRATEGIES=phone=hash,*_no=full // map[string]string 以逗号分隔 key=value
+//
+// 可以与 LoadOption 组合使用，使环境变量覆盖配置文件中的值。
+func (o *Option) LoadEnv(prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "_")
+	if err := loadEnv(reflect.ValueOf(o).Elem(), prefix); err != nil {
+		return err
+	}
+	return o.Validate()
+}
+
+// loadEnv 按 `json` 标签递归地从环境变量中读取 struct 字段
+func loadEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := strings.ToUpper(tag)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := loadEnv(fv, name); err != nil {
+				return err
+			}
+			continue
+		}
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(fv, s); err != nil {
+			return fmt.Errorf("rpclient: invalid environment variable %s=%q: %w", name, s, err)
+		}
+	}
+	return nil
+}
+
+// setEnvValue 将环境变量的值转换为字段的类型
+func setEnvValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		m := make(map[string]string)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", item)
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rpclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOption(t *testing.T) {
+	files := map[string]string{
+		"option.json": `{
+  "rpc_address": "127.0.0.1:6001",
+  "codec": "goridge",
+  "log_level": "info",
+  "sensitive_words": ["token", "re:^pwd$"],
+  "mask_strategies": {"phone": "hash"},
+  "timeout": 30,
+  "retry": {"max_attempts": 5, "backoff": {"initial": 200}},
+  "tls": {"enabled": true, "server_name": "rpc.local"},
+  "goridge": {"payload_codec": "json"}
+}`,
+		"option.yaml": `
+rpc_address: 127.0.0.1:6001
+codec: goridge
+log_level: info
+sensitive_words: [token, "re:^pwd$"]
+mask_strategies:
+  phone: hash
+timeout: 30
+retry:
+  max_attempts: 5
+  backoff:
+    initial: 200
+tls:
+  enabled: true
+  server_name: rpc.local
+goridge:
+  payload_codec: json
+`,
+		"option.toml": `
+rpc_address = "127.0.0.1:6001"
+codec = "goridge"
+log_level = "info"
+sensitive_words = ["token", "re:^pwd$"]
+timeout = 30
+
+[mask_strategies]
+phone = "hash"
+
+[retry]
+max_attempts = 5
+
+[retry.backoff]
+initial = 200
+
+[tls]
+enabled = true
+server_name = "rpc.local"
+
+[goridge]
+payload_codec = "json"
+`,
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		opt, err := LoadOption(path)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		// 未设置的字段使用默认值
+		assert.Equal(t, "tcp", opt.Network, name)
+		assert.Equal(t, 1, opt.TimeoutOverhead, name)
+		assert.Equal(t, defaultBackoff, opt.Reconnect.Backoff, name)
+
+		assert.Equal(t, GoridgeCodec, opt.Codec, name)
+		assert.Equal(t, "info", opt.LogLevel, name)
+		assert.Equal(t, []string{"token", "re:^pwd$"}, opt.SensitiveWords, name)
+		assert.Equal(t, map[string]string{"phone": "hash"}, opt.MaskStrategies, name)
+		assert.Equal(t, 30, opt.Timeout, name)
+		assert.Equal(t, 5, opt.Retry.MaxAttempts, name)
+		assert.Equal(t, Backoff{Initial: 200, Max: 10000, Multiplier: 2, Jitter: 0.2}, opt.Retry.Backoff, name)
+		assert.Equal(t, TLSOption{Enabled: true, ServerName: "rpc.local"}, opt.TLS, name)
+		assert.Equal(t, GoridgeJson, opt.Goridge.PayloadCodec, name)
+	}
+
+	// 不修改 defaultOption
+	assert.Equal(t, JsonCodec, defaultOption.Codec)
+	assert.Contains(t, defaultOption.SensitiveWords, "password")
+
+	_, err := LoadOption(filepath.Join(dir, "option.ini"))
+	assert.ErrorContains(t, err, "read option file")
+	path := filepath.Join(dir, "option.ini")
+	assert.NoError(t, os.WriteFile(path, nil, 0o600))
+	_, err = LoadOption(path)
+	assert.EqualError(t, err, `rpclient: unsupported option file format ".ini"`)
+
+	path = filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"codec": "xml"}`), 0o600))
+	_, err = LoadOption(path)
+	assert.ErrorContains(t, err, `unsupported codec "xml"`)
+}
+
+func TestOptionFromEnv(t *testing.T) {
+	t.Setenv("RPCLIENT_CODEC", "msgpack")
+	t.Setenv("RPCLIENT_LOG_LEVEL", "warn")
+	t.Setenv("RPCLIENT_LOG_SAMPLE_RATE", "0.5")
+	t.Setenv("RPCLIENT_LOG_REPLY_ON_ERROR", "true")
+	t.Setenv("RPCLIENT_SENSITIVE_WORDS", "token, secret")
+	t.Setenv("RPCLIENT_MASK_STRATEGIES", "phone=hash,*_no=keep_last:4")
+	t.Setenv("RPCLIENT_RETRY_MAX_ATTEMPTS", "5")
+	t.Setenv("RPCLIENT_RECONNECT_BACKOFF_INITIAL", "500")
+	t.Setenv("RPCLIENT_TLS_CA_FILE", "/etc/rpc/ca.pem")
+
+	opt, err := OptionFromEnv("RPCLIENT_")
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp", opt.Network)
+	assert.Equal(t, MsgpackCodec, opt.Codec)
+	assert.Equal(t, "warn", opt.LogLevel)
+	assert.Equal(t, 0.5, opt.LogSampleRate)
+	assert.True(t, opt.LogReplyOnError)
+	assert.Equal(t, []string{"token", "secret"}, opt.SensitiveWords)
+	assert.Equal(t, map[string]string{"phone": "hash", "*_no": "keep_last:4"}, opt.MaskStrategies)
+	assert.Equal(t, 5, opt.Retry.MaxAttempts)
+	assert.Equal(t, 500, opt.Reconnect.Backoff.Initial)
+	assert.Equal(t, "/etc/rpc/ca.pem", opt.TLS.CAFile)
+
+	// 环境变量覆盖配置文件
+	path := filepath.Join(t.TempDir(), "option.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"codec": "goridge", "timeout": 30}`), 0o600))
+	opt, err = LoadOption(path)
+	assert.NoError(t, err)
+	assert.NoError(t, opt.LoadEnv("RPCLIENT"))
+	assert.Equal(t, MsgpackCodec, opt.Codec)
+	assert.Equal(t, 30, opt.Timeout)
+
+	t.Setenv("RPCLIENT_TIMEOUT", "30s")
+	_, err = OptionFromEnv("RPCLIENT")
+	assert.ErrorContains(t, err, `invalid environment variable RPCLIENT_TIMEOUT="30s"`)
+
+	t.Setenv("RPCLIENT_TIMEOUT", "-1")
+	_, err = OptionFromEnv("RPCLIENT")
+	assert.ErrorContains(t, err, "timeout must not be negative")
+}
@@ -0,0 +1,59 @@
+package rpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// DialFunc 建立到 RPC 服务端的连接，可用于代理、net.Pipe 等自定义连接方式
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dialConn 建立连接并完成 TLS、压缩握手，整个过程受 ctx 及 Option.DialTimeout 限制，压缩握手另外最多等待 compressHandshakeTimeout
+func (c *RpcClient) dialConn(ctx context.Context) (net.Conn, error) {
+	if c.option.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.option.DialTimeout)*time.Second)
+		defer cancel()
+	}
+
+	dial := c.option.DialContext
+	if dial == nil {
+		dialer := &net.Dialer{KeepAlive: time.Duration(c.option.KeepAlive) * time.Second}
+		dial = dialer.DialContext
+	}
+	conn, err := dial(ctx, c.option.Network, c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// TLS、压缩握手同样不能超过连接超时时间
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if c.tlsConfig != nil {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if c.option.Compression != "" {
+		// 未设置 DialTimeout 时握手也不能一直等待
+		deadline := time.Now().Add(compressHandshakeTimeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		_ = conn.SetReadDeadline(deadline)
+		cc, err := newCompressConn(conn, c.option.Compression, c.option.CompressionThreshold)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = cc
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
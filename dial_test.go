@@ -0,0 +1,66 @@
+package rpclient
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRpcClient_DialContext(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Test", &testService{calls: make(map[string]int)}); err != nil {
+		t.Fatal(err)
+	}
+	var dialed []string
+	opt := testOption
+	opt.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, network+"://"+addr)
+		clientConn, serverConn := net.Pipe()
+		go server.ServeCodec(jsonrpc.NewServerCodec(serverConn))
+		return clientConn, nil
+	}
+	client := newTestClient(t, "pipe", &opt)
+
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), "pipe")), &r))
+	assert.Equal(t, "pipe", r.Results[0].Data)
+	assert.Equal(t, []string{"tcp://pipe"}, dialed)
+}
+
+func TestRpcClient_DialTimeout(t *testing.T) {
+	opt := testOption
+	opt.DialTimeout = 1
+	opt.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	start := time.Now()
+	_, err := NewClient("unreachable", &opt)
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// 服务端不响应压缩握手
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	opt.DialContext = nil
+	opt.Compression = GzipCompression
+	start = time.Now()
+	_, err = NewClient(ln.Addr().String(), &opt)
+	assert.ErrorContains(t, err, "i/o timeout")
+	assert.Less(t, time.Since(start), 2*time.Second)
+	_ = (<-accepted).Close()
+}
@@ -0,0 +1,81 @@
+package rpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"strings"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+)
+
+// TimeoutError 调用超时错误
+//
+// 由 CallContext 在 ctx 超过截止时间时返回，并被包装为 rrse.TimeOut 类型的错误。
+type TimeoutError struct {
+	ServiceMethod string        // 服务方法
+	Elapsed       time.Duration // 放弃调用前已等待的时间，从调用开始计时，包含之前的重试
+	StoreIds      []string      // 超出自身超时时间（Store.Timeout）的店铺 ID
+	Err           error         // 原始错误，通常为 context.DeadlineExceeded
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("rpclient: %s timed out after %s", e.ServiceMethod, e.Elapsed.Round(time.Millisecond))
+	if len(e.StoreIds) > 0 {
+		msg += fmt.Sprintf(", stores exceeded their timeout: %s", strings.Join(e.StoreIds, ", "))
+	}
+	return msg
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout 实现 net.Error 的超时判断
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// cause 返回被 rrse.E 层层包装的原始错误
+func cause(err error) error {
+	for {
+		e, ok := err.(*rrse.Error)
+		if !ok || e.Err == nil {
+			return err
+		}
+		err = e.Err
+	}
+}
+
+// AsTimeoutError 从调用返回的错误中提取 *TimeoutError
+func AsTimeoutError(err error) (*TimeoutError, bool) {
+	var te *TimeoutError
+	if errors.As(cause(err), &te) {
+		return te, true
+	}
+	return nil, false
+}
+
+var (
+	ErrClosed   = errors.New("rpclient: client is closed")       // 客户端已关闭
+	ErrNotReady = errors.New("rpclient: client is reconnecting") // 连接断开，正在重连
+)
+
+// ErrPoolClosed 连接池已关闭
+var ErrPoolClosed = errors.New("rpclient: pool is closed")
+
+var (
+	ErrClusterClosed = errors.New("rpclient: cluster client is closed") // 多端点客户端已关闭
+	ErrNoEndpoint    = errors.New("rpclient: no endpoint available")    // 没有可用的端点
+)
+
+// isConnError 判断错误是否由连接断开导致
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	err = cause(err)
+	return errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,195 @@
+package rpclient
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+
+	"github.com/goccy/go-json"
+	"github.com/roadrunner-server/goridge/v3/pkg/frame"
+	"github.com/roadrunner-server/goridge/v3/pkg/relay"
+	goridgeRpc "github.com/roadrunner-server/goridge/v3/pkg/rpc"
+	"github.com/roadrunner-server/goridge/v3/pkg/socket"
+	"google.golang.org/protobuf/proto"
+)
+
+// goridge 负载编码
+const (
+	GoridgeGob     = "gob"
+	GoridgeJson    = "json"
+	GoridgeMsgpack = "msgpack"
+	GoridgeProto   = "proto"
+	GoridgeRaw     = "raw"
+)
+
+// goridgePayloadCodecs 负载编码 => 帧标志
+var goridgePayloadCodecs = map[string]byte{
+	GoridgeGob:     frame.CodecGob,
+	GoridgeJson:    frame.CodecJSON,
+	GoridgeMsgpack: frame.CodecMsgpack,
+	GoridgeProto:   frame.CodecProto,
+	GoridgeRaw:     frame.CodecRaw,
+}
+
+// GoridgeOption goridge 编解码器配置，需要与服务端（如 RoadRunner PHP 端）的配置一致
+type GoridgeOption struct {
+	PayloadCodec string `json:"payload_codec" yaml:"payload_codec" toml:"payload_codec"` // 请求负载的编码：gob、json、msgpack、proto、raw，默认 gob
+}
+
+// flags 返回请求帧的标志
+func (o GoridgeOption) flags() (byte, error) {
+	codec := o.PayloadCodec
+	if codec == "" {
+		codec = GoridgeGob
+	}
+	flags, ok := goridgePayloadCodecs[codec]
+	if !ok {
+		return 0, fmt.Errorf("rpclient: unknown goridge payload codec %q", o.PayloadCodec)
+	}
+	return flags, nil
+}
+
+// newGoridgeClientCodec 根据配置创建 goridge 编解码器，未配置时使用 goridge 默认的编解码器
+func newGoridgeClientCodec(conn io.ReadWriteCloser, opt GoridgeOption) (rpc.ClientCodec, error) {
+	if opt.PayloadCodec == "" {
+		return goridgeRpc.NewClientCodec(conn), nil
+	}
+	flags, err := opt.flags()
+	if err != nil {
+		return nil, err
+	}
+	return &goridgeClientCodec{relay: socket.NewSocketRelay(conn), flags: flags}, nil
+}
+
+// goridgeClientCodec 可以选择负载编码的 goridge 编解码器
+//
+// 响应按服务端返回的帧标志解码，msgpack 同 MsgpackCodec 按 `json` 标签解码。
+// proto 编码要求参数为 proto.Message，raw 编码要求参数为 []byte 或 *[]byte，
+// 此时需要通过 RpcClient.CallRaw 调用。
+type goridgeClientCodec struct {
+	relay relay.Relay
+	flags byte
+	frame *frame.Frame
+}
+
+func (c *goridgeClientCodec) WriteRequest(r *rpc.Request, body any) error {
+	var buf bytes.Buffer
+	buf.WriteString(r.ServiceMethod)
+	if body != nil {
+		if err := c.encode(&buf, body); err != nil {
+			return err
+		}
+	}
+
+	fr := frame.NewFrame()
+	fr.WriteFlags(fr.Header(), c.flags)
+	// SEQ_ID + METHOD_NAME_LEN
+	fr.WriteOptions(fr.HeaderPtr(), uint32(r.Seq), uint32(len(r.ServiceMethod)))
+	fr.WriteVersion(fr.Header(), frame.Version1)
+	fr.WritePayloadLen(fr.Header(), uint32(buf.Len()))
+	fr.WritePayload(buf.Bytes())
+	fr.WriteCRC(fr.Header())
+	return c.relay.Send(fr)
+}
+
+// encode 按配置的负载编码写入 body
+func (c *goridgeClientCodec) encode(buf *bytes.Buffer, body any) error {
+	switch {
+	case c.flags&frame.CodecProto != 0:
+		m, ok := body.(proto.Message)
+		if !ok {
+			return fmt.Errorf("rpclient: goridge proto payload requires proto.Message, got %T", body)
+		}
+		b, err := proto.Marshal(m)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case c.flags&frame.CodecJSON != 0:
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case c.flags&frame.CodecRaw != 0:
+		switch b := body.(type) {
+		case []byte:
+			buf.Write(b)
+		case *[]byte:
+			buf.Write(*b)
+		default:
+			return fmt.Errorf("rpclient: goridge raw payload requires []byte, got %T", body)
+		}
+	case c.flags&frame.CodecMsgpack != 0:
+		return newMsgpackEncoder(buf).Encode(body)
+	default:
+		return gob.NewEncoder(buf).Encode(body)
+	}
+	return nil
+}
+
+func (c *goridgeClientCodec) ReadResponseHeader(r *rpc.Response) error {
+	fr := frame.NewFrame()
+	if err := c.relay.Receive(fr); err != nil {
+		return err
+	}
+	if !fr.VerifyCRC(fr.Header()) {
+		return errors.New("rpclient: goridge frame CRC verification failed")
+	}
+	opts := fr.ReadOptions(fr.Header())
+	if len(opts) != 2 || int(opts[1]) > len(fr.Payload()) {
+		return errors.New("rpclient: goridge frame should have 2 options, SEQ_ID and METHOD_LEN")
+	}
+
+	c.frame = fr
+	r.Seq = uint64(opts[0])
+	r.ServiceMethod = string(fr.Payload()[:opts[1]])
+	if fr.ReadFlags()&frame.ERROR != 0 {
+		r.Error = string(fr.Payload()[opts[1]:])
+	}
+	return nil
+}
+
+func (c *goridgeClientCodec) ReadResponseBody(out any) error {
+	fr := c.frame
+	c.frame = nil
+	if out == nil || fr == nil {
+		return nil
+	}
+	payload := fr.Payload()[fr.ReadOptions(fr.Header())[1]:]
+	if len(payload) == 0 {
+		return nil
+	}
+
+	flags := fr.ReadFlags()
+	switch {
+	case flags&frame.CodecProto != 0:
+		m, ok := out.(proto.Message)
+		if !ok {
+			return fmt.Errorf("rpclient: goridge proto reply requires proto.Message, got %T", out)
+		}
+		return proto.Unmarshal(payload, m)
+	case flags&frame.CodecJSON != 0:
+		return json.Unmarshal(payload, out)
+	case flags&frame.CodecRaw != 0:
+		raw, ok := out.(*[]byte)
+		if !ok {
+			return fmt.Errorf("rpclient: goridge raw reply requires *[]byte, got %T", out)
+		}
+		*raw = append(*raw, payload...)
+		return nil
+	case flags&frame.CodecMsgpack != 0:
+		return newMsgpackDecoder(bytes.NewReader(payload)).Decode(out)
+	case flags&frame.CodecGob != 0:
+		return gob.NewDecoder(bytes.NewReader(payload)).Decode(out)
+	default:
+		return errors.New("rpclient: unknown goridge payload codec in reply")
+	}
+}
+
+func (c *goridgeClientCodec) Close() error {
+	return c.relay.Close()
+}
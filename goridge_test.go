@@ -0,0 +1,104 @@
+package rpclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// goridgeService 测试 goridge 各负载编码的服务
+type goridgeService struct{}
+
+// Echo 以 map 形式接收参数，用于 msgpack 等按键名解码的负载
+func (s *goridgeService) Echo(args []map[string]any, reply *map[string]any) error {
+	results := make([]any, 0, len(args))
+	for _, arg := range args {
+		store, _ := arg["store"].(map[string]any)
+		results = append(results, map[string]any{
+			"store_id": store["id"],
+			"ok":       true,
+			"data":     arg["body"],
+		})
+	}
+	*reply = map[string]any{"request_id": "goridge", "results": results}
+	return nil
+}
+
+func (s *goridgeService) Raw(args []byte, reply *[]byte) error {
+	*reply = append([]byte("raw:"), args...)
+	return nil
+}
+
+func (s *goridgeService) Proto(args *wrapperspb.StringValue, reply *wrapperspb.StringValue) error {
+	reply.Value = "proto:" + args.Value
+	return nil
+}
+
+func newGoridgeTestServer(t *testing.T) *testServer {
+	ts := newTestServerCodec(t, serverCodecs[GoridgeCodec])
+	if err := ts.server.RegisterName("Goridge", &goridgeService{}); err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestRpcClient_GoridgePayloadCodec(t *testing.T) {
+	ts := newGoridgeTestServer(t)
+	tests := []struct {
+		goridge       GoridgeOption
+		serviceMethod string
+	}{
+		{GoridgeOption{}, "Test.Echo"},
+		{GoridgeOption{PayloadCodec: GoridgeGob}, "Test.Echo"},
+		{GoridgeOption{PayloadCodec: GoridgeJson}, "Test.Echo"},
+		{GoridgeOption{PayloadCodec: GoridgeMsgpack}, "Goridge.Echo"},
+	}
+	for _, tt := range tests {
+		opt := testOption
+		opt.Codec = GoridgeCodec
+		opt.Goridge = tt.goridge
+		client := newTestClient(t, ts.addr, &opt)
+
+		var r Reply
+		err := client.Call(tt.serviceMethod, NewArgs().Add(NewPayload(testStore("1"), "hello")), &r)
+		assert.NoError(t, err, tt.goridge.PayloadCodec)
+		assert.Len(t, r.Results, 1, tt.goridge.PayloadCodec)
+		assert.Equal(t, "1", r.Results[0].StoreId, tt.goridge.PayloadCodec)
+		assert.Equal(t, "hello", r.Results[0].Data, tt.goridge.PayloadCodec)
+
+		err = client.Call("Test.Unknown", NewArgs().Add(NewPayload(testStore("1"))), &r)
+		assert.ErrorContains(t, err, "can't find method Test.Unknown", tt.goridge.PayloadCodec)
+	}
+}
+
+func TestRpcClient_GoridgeRawAndProto(t *testing.T) {
+	ts := newGoridgeTestServer(t)
+	opt := testOption
+	opt.Codec = GoridgeCodec
+
+	opt.Goridge = GoridgeOption{PayloadCodec: GoridgeRaw}
+	client := newTestClient(t, ts.addr, &opt)
+	var raw []byte
+	assert.NoError(t, client.CallRaw(context.Background(), "Goridge.Raw", []byte("abc"), &raw))
+	assert.Equal(t, "raw:abc", string(raw))
+	assert.ErrorContains(t, client.Call("Goridge.Raw", NewArgs(), &Reply{}), "requires []byte")
+
+	opt.Goridge = GoridgeOption{PayloadCodec: GoridgeProto}
+	client = newTestClient(t, ts.addr, &opt)
+	var reply wrapperspb.StringValue
+	assert.NoError(t, client.CallRaw(context.Background(), "Goridge.Proto", wrapperspb.String("abc"), &reply))
+	assert.Equal(t, "proto:abc", reply.Value)
+}
+
+func TestGoridgeOption_flags(t *testing.T) {
+	_, err := GoridgeOption{PayloadCodec: "xml"}.flags()
+	assert.EqualError(t, err, `rpclient: unknown goridge payload codec "xml"`)
+
+	opt := testOption
+	opt.Codec = GoridgeCodec
+	opt.Goridge = GoridgeOption{PayloadCodec: "xml"}
+	_, err = NewClient(newGoridgeTestServer(t).addr, &opt)
+	assert.ErrorContains(t, err, "unknown goridge payload codec")
+}
@@ -0,0 +1,39 @@
+package rpclient
+
+import (
+	"context"
+)
+
+// Invoker 执行一次调用
+type Invoker func(ctx context.Context, serviceMethod string, args Args, reply *Reply) error
+
+// UnaryInterceptor 调用拦截器
+//
+// 拦截器可以在调用 invoker 前后执行额外的逻辑（如统计指标、刷新令牌、审计），
+// 也可以不调用 invoker 直接返回。
+type UnaryInterceptor func(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error
+
+// ChainInterceptors 将多个拦截器按顺序组合为一个，第一个拦截器最先执行
+func ChainInterceptors(interceptors ...UnaryInterceptor) UnaryInterceptor {
+	return func(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+		return chainInvoker(interceptors, invoker)(ctx, serviceMethod, args, reply)
+	}
+}
+
+// chainInvoker 将拦截器包装到 invoker 外层
+func chainInvoker(interceptors []UnaryInterceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+			return interceptor(ctx, serviceMethod, args, reply, next)
+		}
+	}
+	return invoker
+}
+
+// loggingInterceptor 默认拦截器，记录脱敏后的调用日志
+func (c *RpcClient) loggingInterceptor(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+	err := invoker(ctx, serviceMethod, args, reply)
+	c.logCall(serviceMethod, args, reply, err)
+	return err
+}
@@ -0,0 +1,44 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRpcClient_Interceptors(t *testing.T) {
+	ts := newTestServer(t)
+
+	var trace []string
+	record := func(name string) UnaryInterceptor {
+		return func(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+			trace = append(trace, name+":before:"+serviceMethod)
+			err := invoker(ctx, serviceMethod, args, reply)
+			trace = append(trace, name+":after")
+			return err
+		}
+	}
+	errDenied := errors.New("denied")
+	deny := func(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+		if serviceMethod == "Test.Sleep" {
+			return errDenied
+		}
+		return invoker(ctx, serviceMethod, args, reply)
+	}
+
+	opt := testOption
+	opt.Interceptors = []UnaryInterceptor{record("a"), ChainInterceptors(record("b"), deny)}
+	client := newTestClient(t, ts.addr, &opt)
+
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	assert.Equal(t, 1, len(r.Results))
+	assert.Equal(t, []string{"a:before:Test.Echo", "b:before:Test.Echo", "b:after", "a:after"}, trace)
+
+	trace = nil
+	assert.ErrorIs(t, client.Call("Test.Sleep", NewArgs().Add(NewPayload(testStore("1"))), &r), errDenied)
+	assert.Equal(t, []string{"a:before:Test.Sleep", "b:before:Test.Sleep", "b:after", "a:after"}, trace)
+	assert.Equal(t, 0, len(r.Results))
+}
@@ -0,0 +1,96 @@
+package rpclient
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+)
+
+// LogStats 调用日志的采样及截断计数
+type LogStats struct {
+	Suppressed uint64 // 因采样未记录的成功调用数
+	Truncated  uint64 // 被截断的 Body、Result.Data 数
+}
+
+type logStats struct {
+	suppressed atomic.Uint64
+	truncated  atomic.Uint64
+}
+
+// LogStats 返回调用日志的采样及截断计数
+func (c *RpcClient) LogStats() LogStats {
+	return LogStats{
+		Suppressed: c.logStats.suppressed.Load(),
+		Truncated:  c.logStats.truncated.Load(),
+	}
+}
+
+// sampleLog 成功的调用是否记录日志
+func (c *RpcClient) sampleLog() bool {
+	rate := c.option.LogSampleRate
+	return rate <= 0 || rate >= 1 || rand.Float64() < rate
+}
+
+// limitLog 按 Option.LogMaxItems、Option.LogMaxBytes 截断脱敏后的数据
+func (c *RpcClient) limitLog(v any) any {
+	truncated := false
+	if n := c.option.LogMaxItems; n > 0 {
+		v = limitItems(v, n, &truncated)
+	}
+	if n := c.option.LogMaxBytes; n > 0 {
+		v = limitBytes(v, n, &truncated)
+	}
+	if truncated {
+		c.logStats.truncated.Add(1)
+	}
+	return v
+}
+
+// limitItems 只保留 slice、map 中的前 n 项，嵌套的数据同样处理
+// v 为 Redactor.Redact 返回的数据，只包含 map[string]any、[]any 及标量
+func limitItems(v any, n int, truncated *bool) any {
+	switch vv := v.(type) {
+	case []any:
+		s := make([]any, 0, min(len(vv), n)+1)
+		for _, item := range vv[:min(len(vv), n)] {
+			s = append(s, limitItems(item, n, truncated))
+		}
+		if len(vv) > n {
+			*truncated = true
+			s = append(s, fmt.Sprintf("...(%d more items)", len(vv)-n))
+		}
+		return s
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(vv))
+		m := make(map[string]any, min(len(vv), n)+1)
+		for _, k := range keys[:min(len(keys), n)] {
+			m[k] = limitItems(vv[k], n, truncated)
+		}
+		if len(vv) > n {
+			*truncated = true
+			m["..."] = fmt.Sprintf("%d more keys", len(vv)-n)
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+// limitBytes JSON 编码后超过 n 字节时，替换为截断后的 JSON 字符串
+func limitBytes(v any, n int, truncated *bool) any {
+	b, err := json.Marshal(v)
+	if err != nil || len(b) <= n {
+		return v
+	}
+	*truncated = true
+	cut := n
+	for cut > 0 && !utf8.RuneStart(b[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(truncated, %d bytes)", b[:cut], len(b))
+}
@@ -0,0 +1,79 @@
+package rpclient
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitItems(t *testing.T) {
+	var truncated bool
+	v := limitItems(map[string]any{
+		"a": []any{1, 2, 3},
+		"b": "x",
+		"c": "y",
+	}, 2, &truncated)
+	assert.True(t, truncated)
+	assert.Equal(t, map[string]any{
+		"a":   []any{1, 2, "...(1 more items)"},
+		"b":   "x",
+		"...": "1 more keys",
+	}, v)
+
+	truncated = false
+	assert.Equal(t, []any{1}, limitItems([]any{1}, 2, &truncated))
+	assert.False(t, truncated)
+}
+
+func TestLimitBytes(t *testing.T) {
+	var truncated bool
+	assert.Equal(t, "abc", limitBytes("abc", 10, &truncated))
+	assert.False(t, truncated)
+
+	v := limitBytes(map[string]any{"name": "中文名称"}, 12, &truncated)
+	assert.True(t, truncated)
+	assert.Equal(t, `{"name":"中...(truncated, 23 bytes)`, v)
+}
+
+func TestRpcClient_logLimits(t *testing.T) {
+	ts := newTestServer(t)
+
+	var buf bytes.Buffer
+	opt := testOption
+	opt.LogHandler = slog.NewJSONHandler(&buf, nil)
+	opt.LogMaxItems = 3
+	opt.LogMaxBytes = 64
+	opt.LogReplyOnError = true
+	client := newTestClient(t, ts.addr, &opt)
+
+	items := make([]any, 100)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%d", i)
+	}
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), items)), &r))
+	assert.Equal(t, 100, len(r.Results[0].Data.([]any)))
+	assert.Contains(t, buf.String(), `"body":["item-0","item-1","item-2","...(97 more items)"]`)
+	assert.NotContains(t, buf.String(), `"reply"`)
+	assert.Contains(t, buf.String(), `"results":1`)
+	assert.Equal(t, uint64(1), client.LogStats().Truncated)
+
+	buf.Reset()
+	assert.Error(t, client.Call("Test.Unknown", NewArgs().Add(NewPayload(testStore("1"), strings.Repeat("x", 100))), &r))
+	assert.Contains(t, buf.String(), `"reply"`)
+	assert.Contains(t, buf.String(), `truncated, 102 bytes`)
+
+	// 采样
+	buf.Reset()
+	opt.LogSampleRate = 0.000001
+	client = newTestClient(t, ts.addr, &opt)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	}
+	assert.Equal(t, uint64(10), client.LogStats().Suppressed)
+	assert.Empty(t, buf.String())
+}
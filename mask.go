@@ -0,0 +1,110 @@
+package rpclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	MaskPartial  = "partial"   // 保留首尾部分字符
+	MaskFull     = "full"      // 全部替换
+	MaskHash     = "hash"      // SHA-256 指纹，便于关联同一值
+	MaskLength   = "length"    // 只保留长度
+	MaskKeepLast = "keep_last" // 只保留最后 N 个字符，写作 `keep_last:N`，默认 N 为 4
+)
+
+// Masker 掩码方式，返回 s 掩码后的结果
+type Masker func(s string) string
+
+var (
+	maskersMu sync.RWMutex
+	maskers   = map[string]Masker{
+		MaskPartial: maskString,
+		MaskFull:    maskFull,
+		MaskHash:    maskHash,
+		MaskLength:  maskLength,
+	}
+)
+
+// RegisterMasker 注册自定义掩码方式，注册后可在 Option.MaskStrategies 及 `mask` 标签中按名称使用
+func RegisterMasker(name string, masker Masker) {
+	maskersMu.Lock()
+	defer maskersMu.Unlock()
+	maskers[name] = masker
+}
+
+// lookupMasker 根据名称返回掩码方式，`keep_last:N` 形式的名称带有参数
+func lookupMasker(strategy string) (Masker, error) {
+	name, arg, hasArg := strings.Cut(strategy, ":")
+	if name == MaskKeepLast {
+		n := 4
+		if hasArg {
+			var err error
+			if n, err = strconv.Atoi(arg); err != nil || n < 0 {
+				return nil, fmt.Errorf("rpclient: invalid mask strategy %q", strategy)
+			}
+		}
+		return maskKeepLast(n), nil
+	}
+
+	maskersMu.RLock()
+	defer maskersMu.RUnlock()
+	masker, ok := maskers[strategy]
+	if !ok {
+		return nil, fmt.Errorf("rpclient: unknown mask strategy %q", strategy)
+	}
+	return masker, nil
+}
+
+// maskString 保留首尾部分字符，按字符（rune）而非字节计算，中文等多字节字符不会被截断
+// 不超过 2 个字符时全部替换；不超过 6 个字符时保留首尾各 1 个字符，
+// 避免短字符串（如 6 位密码）保留一半后完全未被掩码；更长的字符串保留首尾各 25%，至少 3 个字符
+func maskString(s string) string {
+	runes := []rune(s)
+	n := len(runes)
+	if n <= 2 {
+		return strings.Repeat("*", n)
+	}
+
+	var keep int
+	if n <= 6 {
+		keep = 1
+	} else {
+		keep = n / 4
+		if keep < 3 {
+			keep = 3
+		}
+	}
+	return string(runes[:keep]) + strings.Repeat("*", n-keep*2) + string(runes[n-keep:])
+}
+
+// maskFull 全部替换，不保留长度信息
+func maskFull(string) string {
+	return "******"
+}
+
+// maskHash 取 SHA-256 的前 8 个字节作为指纹
+func maskHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// maskLength 只保留长度
+func maskLength(s string) string {
+	return fmt.Sprintf("[%d chars]", len([]rune(s)))
+}
+
+// maskKeepLast 只保留最后 n 个字符，字符串长度不超过 2n 时全部替换
+func maskKeepLast(n int) Masker {
+	return func(s string) string {
+		runes := []rune(s)
+		if len(runes) <= n*2 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-n) + string(runes[len(runes)-n:])
+	}
+}
@@ -0,0 +1,45 @@
+package rpclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupMasker(t *testing.T) {
+	tests := []struct {
+		strategy string
+		in       string
+		want     string
+	}{
+		{MaskPartial, "abcdefghijkl", "abc******jkl"},
+		{MaskFull, "abc", "******"},
+		{MaskLength, "密码abc", "[5 chars]"},
+		{MaskKeepLast, "6222020200112233", "************2233"},
+		{"keep_last:2", "abcdef", "****ef"},
+		{"keep_last:2", "abcd", "****"},
+		{"keep_last:0", "abcd", "****"},
+	}
+	for _, tt := range tests {
+		masker, err := lookupMasker(tt.strategy)
+		assert.NoError(t, err)
+		assert.Equalf(t, tt.want, masker(tt.in), "%s(%s)", tt.strategy, tt.in)
+	}
+
+	masker, err := lookupMasker(MaskHash)
+	assert.NoError(t, err)
+	assert.Equal(t, masker("a"), masker("a"))
+	assert.NotEqual(t, masker("a"), masker("b"))
+	assert.True(t, strings.HasPrefix(masker("a"), "sha256:"))
+
+	for _, strategy := range []string{"unknown", "keep_last:x", "keep_last:-1"} {
+		_, err = lookupMasker(strategy)
+		assert.Errorf(t, err, "%s", strategy)
+	}
+
+	RegisterMasker("redacted", func(string) string { return "[REDACTED]" })
+	masker, err = lookupMasker("redacted")
+	assert.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", masker("abc"))
+}
@@ -0,0 +1,250 @@
+package rpclient
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackRequest MessagePack 编解码器的请求帧，结构同 JSON-RPC
+type msgpackRequest struct {
+	Method string `msgpack:"method"`
+	Params [1]any `msgpack:"params"`
+	Id     uint64 `msgpack:"id"`
+}
+
+// msgpackResponse MessagePack 编解码器的响应帧，结构同 JSON-RPC
+type msgpackResponse struct {
+	Id     uint64             `msgpack:"id"`
+	Result msgpack.RawMessage `msgpack:"result"`
+	Error  any                `msgpack:"error"`
+}
+
+// msgpackClientCodec 使用 MessagePack 编码的 rpc.ClientCodec
+//
+// 每个请求、响应都是一个独立的 MessagePack map，结构同 JSON-RPC：
+// 请求为 {"method", "params", "id"}，响应为 {"id", "result", "error"}。
+// 参数及结果按 `json` 标签编解码（存在 `msgpack` 标签时优先），
+// 结果中键不是字符串的 map 会被转换为 map[string]any。
+type msgpackClientCodec struct {
+	c   io.ReadWriteCloser
+	buf bytes.Buffer // 每个请求编码后一次写入连接
+	enc *msgpack.Encoder
+	dec *msgpack.Decoder
+
+	resp msgpackResponse
+
+	mu      sync.Mutex
+	pending map[uint64]string // 请求 ID => ServiceMethod
+}
+
+// newMsgpackClientCodec 创建 MessagePack 编解码器
+func newMsgpackClientCodec(conn io.ReadWriteCloser) rpc.ClientCodec {
+	c := &msgpackClientCodec{
+		c:       conn,
+		dec:     newMsgpackDecoder(bufio.NewReader(conn)),
+		pending: make(map[uint64]string),
+	}
+	c.enc = newMsgpackEncoder(&c.buf)
+	return c
+}
+
+// newMsgpackEncoder 创建按 `json` 标签编码的 MessagePack 编码器
+func newMsgpackEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	return enc
+}
+
+// newMsgpackDecoder 创建按 `json` 标签解码、map 解码为 map[string]any 的 MessagePack 解码器
+func newMsgpackDecoder(r io.Reader) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	dec.SetMapDecoder(decodeMsgpackMap)
+	return dec
+}
+
+func (c *msgpackClientCodec) WriteRequest(r *rpc.Request, param any) error {
+	c.mu.Lock()
+	c.pending[r.Seq] = r.ServiceMethod
+	c.mu.Unlock()
+	req := msgpackRequest{Method: r.ServiceMethod, Params: [1]any{param}, Id: r.Seq}
+	c.buf.Reset()
+	if err := c.enc.Encode(&req); err != nil {
+		return err
+	}
+	_, err := c.c.Write(c.buf.Bytes())
+	return err
+}
+
+func (c *msgpackClientCodec) ReadResponseHeader(r *rpc.Response) error {
+	c.resp = msgpackResponse{}
+	if err := c.dec.Decode(&c.resp); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	r.ServiceMethod = c.pending[c.resp.Id]
+	delete(c.pending, c.resp.Id)
+	c.mu.Unlock()
+
+	r.Seq = c.resp.Id
+	r.Error = ""
+	if c.resp.Error != nil {
+		s, ok := c.resp.Error.(string)
+		if !ok {
+			s = fmt.Sprint(c.resp.Error)
+		}
+		if s == "" {
+			s = "unspecified error"
+		}
+		r.Error = s
+	}
+	return nil
+}
+
+func (c *msgpackClientCodec) ReadResponseBody(x any) error {
+	if x == nil {
+		return nil
+	}
+	if len(c.resp.Result) == 0 {
+		return errors.New("rpclient: msgpack response has no result")
+	}
+	return newMsgpackDecoder(bytes.NewReader(c.resp.Result)).Decode(x)
+}
+
+func (c *msgpackClientCodec) Close() error {
+	return c.c.Close()
+}
+
+// msgpackServerRequest 服务端读取的请求帧，参数在读取请求体时再解码
+type msgpackServerRequest struct {
+	Method string               `msgpack:"method"`
+	Params []msgpack.RawMessage `msgpack:"params"`
+	Id     uint64               `msgpack:"id"`
+}
+
+// msgpackServerResponse 服务端写入的响应帧
+type msgpackServerResponse struct {
+	Id     uint64 `msgpack:"id"`
+	Result any    `msgpack:"result"`
+	Error  any    `msgpack:"error"`
+}
+
+// msgpackServerCodec 与 MsgpackCodec 配套的 rpc.ServerCodec
+type msgpackServerCodec struct {
+	c   io.Closer
+	w   *bufio.Writer
+	enc *msgpack.Encoder
+	dec *msgpack.Decoder
+	req msgpackServerRequest
+}
+
+// NewMsgpackServerCodec 创建与 MsgpackCodec 配套的服务端编解码器，用于 Go 实现的 net/rpc 服务端：
+//
+//	go server.ServeCodec(rpclient.NewMsgpackServerCodec(conn))
+//
+// 帧格式见 msgpackClientCodec，参数及结果同样按 `json` 标签编解码，参数中的 map 解码为 map[string]any。
+// 其他语言的服务端需要按相同的帧格式实现。
+func NewMsgpackServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	w := bufio.NewWriter(conn)
+	return &msgpackServerCodec{
+		c:   conn,
+		w:   w,
+		enc: newMsgpackEncoder(w),
+		dec: newMsgpackDecoder(bufio.NewReader(conn)),
+	}
+}
+
+func (c *msgpackServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	c.req = msgpackServerRequest{}
+	if err := c.dec.Decode(&c.req); err != nil {
+		return err
+	}
+	r.ServiceMethod, r.Seq = c.req.Method, c.req.Id
+	return nil
+}
+
+func (c *msgpackServerCodec) ReadRequestBody(x any) error {
+	if x == nil || len(c.req.Params) == 0 {
+		return nil
+	}
+	return newMsgpackDecoder(bytes.NewReader(c.req.Params[0])).Decode(x)
+}
+
+func (c *msgpackServerCodec) WriteResponse(r *rpc.Response, x any) error {
+	resp := msgpackServerResponse{Id: r.Seq, Result: x}
+	if r.Error != "" {
+		resp.Result, resp.Error = nil, r.Error
+	}
+	if err := c.enc.Encode(&resp); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+func (c *msgpackServerCodec) Close() error {
+	return c.c.Close()
+}
+
+// decodeMsgpackMap 将 MessagePack map 解码为 map[string]any，非字符串的键使用 fmt.Sprint 转换
+// PHP 等语言中的数字索引数组会被编码为整数键的 map
+func decodeMsgpackMap(d *msgpack.Decoder) (any, error) {
+	m, err := d.DecodeUntypedMap()
+	if err != nil || m == nil {
+		return nil, err
+	}
+	// 嵌套的 map 已经由 decodeMsgpackMap 解码，只需转换当前层的键
+	normalized := make(map[string]any, len(m))
+	for k, v := range m {
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		normalized[key] = v
+	}
+	return normalized, nil
+}
+
+// normalizeMap 将 map[any]any 转换为 map[string]any
+func normalizeMap(m map[any]any) map[string]any {
+	normalized := make(map[string]any, len(m))
+	for k, v := range m {
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		normalized[key] = normalizeData(v)
+	}
+	return normalized
+}
+
+// normalizeData 返回将 v 中的 map[any]any 递归转换为 map[string]any 后的值，便于使用 JSON 编码
+// 不修改 v
+func normalizeData(v any) any {
+	switch vv := v.(type) {
+	case map[any]any:
+		return normalizeMap(vv)
+	case map[string]any:
+		normalized := make(map[string]any, len(vv))
+		for k, item := range vv {
+			normalized[k] = normalizeData(item)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(vv))
+		for i, item := range vv {
+			normalized[i] = normalizeData(item)
+		}
+		return normalized
+	default:
+		return v
+	}
+}
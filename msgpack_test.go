@@ -0,0 +1,71 @@
+package rpclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// msgpackService 模拟 PHP 服务端返回数字索引数组
+type msgpackService struct{}
+
+func (s *msgpackService) IntKeys(args Args, reply *Reply) error {
+	for _, arg := range args {
+		reply.Results = append(reply.Results, Result{StoreId: arg.Store.ID, Ok: true, Data: map[int]string{1: "a", 2: "b"}})
+	}
+	return nil
+}
+
+func TestRpcClient_MsgpackCodec(t *testing.T) {
+	ts := newTestServerCodec(t, NewMsgpackServerCodec)
+	if err := ts.server.RegisterName("Msgpack", &msgpackService{}); err != nil {
+		t.Fatal(err)
+	}
+	opt := testOption
+	opt.Codec = MsgpackCodec
+	client := newTestClient(t, ts.addr, &opt)
+
+	type order struct {
+		Sn    string  `json:"sn"`
+		Items []int   `json:"items"`
+		Price float64 `json:"price"`
+	}
+	store := testStore("1")
+	store.Configuration["fail"] = true
+	args := NewArgs().
+		Add(NewPayload(testStore("1"), map[string]any{"sn": "A001", "items": []int{1, 2}, "price": 9.5})).
+		Add(NewPayload(store, map[int]string{1: "a", 2: "b"}))
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", args, &r))
+	assert.Len(t, r.Results, 2)
+	assert.True(t, r.Results[0].Ok)
+	assert.False(t, r.Results[0].Error.Valid)
+	assert.False(t, r.Results[1].Ok)
+	assert.Equal(t, "failed", r.Results[1].Error.String)
+
+	var o order
+	assert.NoError(t, r.Results[0].ConvertDataTo(&o))
+	assert.Equal(t, order{Sn: "A001", Items: []int{1, 2}, Price: 9.5}, o)
+
+	// 整数键的 map 解码为 map[string]any
+	assert.Equal(t, map[string]any{"1": "a", "2": "b"}, r.Results[1].Data)
+	var m map[string]string
+	assert.NoError(t, r.Results[1].ConvertDataTo(&m))
+	assert.Equal(t, map[string]string{"1": "a", "2": "b"}, m)
+
+	// 服务端返回的整数键 map 同样转换为 map[string]any
+	assert.NoError(t, client.Call("Msgpack.IntKeys", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	assert.Equal(t, map[string]any{"1": "a", "2": "b"}, r.Results[0].Data)
+
+	err := client.Call("Test.Unknown", args, &r)
+	assert.ErrorContains(t, err, "can't find method Test.Unknown")
+}
+
+func TestResult_ConvertDataTo_untypedMap(t *testing.T) {
+	r := Result{Data: map[any]any{"sn": "A001", 1: []any{map[any]any{"id": int8(1)}}}}
+	var dst map[string]any
+	assert.NoError(t, r.ConvertDataTo(&dst))
+	assert.Equal(t, map[string]any{"sn": "A001", "1": []any{map[string]any{"id": float64(1)}}}, dst)
+	_, ok := r.Data.(map[any]any)
+	assert.True(t, ok)
+}
@@ -1,23 +1,82 @@
 package rpclient
 
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
 const (
 	JsonCodec    = "json"
 	GoridgeCodec = "goridge"
+	MsgpackCodec = "msgpack"
 )
 
-// Option NetWork Known networks are "tcp", "tcp4" (IPv4-only), "tcp6" (IPv6-only), "udp", "udp4" (IPv4-only), "udp6" (IPv6-only), "ip", "ip4" (IPv4-only), "ip6" (IPv6-only), "unix", "unixgram" and "unixpacket".
-// Codec supported codecs are "goridge" and "json"
+// networks 支持的网络类型，编解码器均基于字节流，不支持 udp、ip、unixgram、unixpacket
+var networks = []string{"tcp", "tcp4", "tcp6", "unix"}
+
+// codecs 支持的编解码器
+var codecs = []string{JsonCodec, GoridgeCodec, MsgpackCodec}
+
+// logLevels 支持的日志级别
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Option NetWork supported networks are "tcp", "tcp4" (IPv4-only), "tcp6" (IPv6-only) and "unix".
+// Codec supported codecs are "goridge", "json" and "msgpack"
+//
+// Network、Codec、LogLevel 为空时分别使用 "tcp"、"json"、"debug"，TimeoutOverhead 为 0 时使用 1，
+// DialTimeout 为 0 时使用 10，CompressionThreshold 为 0 时使用 1024，其他字段的取值见 Validate。
 type Option struct {
-	Network        string   `json:"network" yaml:"network" toml:"network"`                         // Current only support `tcp`
-	Codec          string   `json:"codec" yaml:"codec" toml:"codec"`                               // Codes: json, goridge
-	LogLevel       string   `json:"log_level" yaml:"log_level" toml:"log_level"`                   // Level: debug, info, warn, error
-	SensitiveWords []string `json:"sensitive_words" yaml:"sensitive_words" toml:"sensitive_words"` // Sensitive words
+	Network              string               `json:"network" yaml:"network" toml:"network"`                                           // Networks: tcp, tcp4, tcp6, unix
+	Codec                string               `json:"codec" yaml:"codec" toml:"codec"`                                                 // Codes: json, goridge, msgpack
+	DialTimeout          int                  `json:"dial_timeout" yaml:"dial_timeout" toml:"dial_timeout"`                            // 建立连接（含 TLS、压缩握手）的超时时间（秒），0 使用默认值 10，负数表示不限制
+	KeepAlive            int                  `json:"keep_alive" yaml:"keep_alive" toml:"keep_alive"`                                  // TCP keepalive 探测间隔（秒），0 使用系统默认值（15 秒），负数表示禁用
+	TLS                  TLSOption            `json:"tls" yaml:"tls" toml:"tls"`                                                       // TLS 传输配置
+	Goridge              GoridgeOption        `json:"goridge" yaml:"goridge" toml:"goridge"`                                           // goridge 编解码器配置
+	Compression          string               `json:"compression" yaml:"compression" toml:"compression"`                               // 压缩算法：gzip，空表示不压缩；服务端必须使用 AcceptCompression 或实现了相同握手及帧格式的连接包装，否则连接失败
+	CompressionThreshold int                  `json:"compression_threshold" yaml:"compression_threshold" toml:"compression_threshold"` // 超过该字节数的请求、响应才压缩，0 使用默认值 1024，负数表示全部压缩
+	LogLevel             string               `json:"log_level" yaml:"log_level" toml:"log_level"`                                     // Level: debug, info, warn, error
+	LogMaxBytes          int                  `json:"log_max_bytes" yaml:"log_max_bytes" toml:"log_max_bytes"`                         // 日志中每个 Body、Result.Data 的最大字节数（JSON 编码后），0 表示不限制
+	LogMaxItems          int                  `json:"log_max_items" yaml:"log_max_items" toml:"log_max_items"`                         // 日志中每个 slice、map 最多记录的项数，0 表示不限制
+	LogReplyOnError      bool                 `json:"log_reply_on_error" yaml:"log_reply_on_error" toml:"log_reply_on_error"`          // 只在调用失败时记录 Reply
+	LogSampleRate        float64              `json:"log_sample_rate" yaml:"log_sample_rate" toml:"log_sample_rate"`                   // 成功调用的日志采样率，取值 0 ~ 1，0 表示全部记录
+	SensitiveWords       []string             `json:"sensitive_words" yaml:"sensitive_words" toml:"sensitive_words"`                   // Sensitive words, 支持通配符及 `re:` 开头的正则表达式，不区分大小写
+	SensitiveDetectors   []string             `json:"sensitive_detectors" yaml:"sensitive_detectors" toml:"sensitive_detectors"`       // 按值识别敏感数据：email, phone, token 或 `re:` 开头的正则表达式
+	MaskStrategies       map[string]string    `json:"mask_strategies" yaml:"mask_strategies" toml:"mask_strategies"`                   // 敏感键的掩码方式，键的规则同 SensitiveWords，值为 partial, full, hash, length, keep_last:N
+	DefaultMaskStrategy  string               `json:"default_mask_strategy" yaml:"default_mask_strategy" toml:"default_mask_strategy"` // 未在 MaskStrategies 中指定的敏感键使用的掩码方式，默认 partial
+	Timeout              int                  `json:"timeout" yaml:"timeout" toml:"timeout"`                                           // 店铺未设置超时时间时使用的默认值（秒），0 表示不限制
+	TimeoutOverhead      int                  `json:"timeout_overhead" yaml:"timeout_overhead" toml:"timeout_overhead"`                // 在店铺超时时间基础上额外等待的时间（秒），0 使用默认值 1
+	Reconnect            ReconnectOption      `json:"reconnect" yaml:"reconnect" toml:"reconnect"`                                     // 断线重连
+	Retry                RetryOption          `json:"retry" yaml:"retry" toml:"retry"`                                                 // 调用重试
+	CircuitBreaker       CircuitBreakerOption `json:"circuit_breaker" yaml:"circuit_breaker" toml:"circuit_breaker"`                   // 按服务方法及店铺熔断
+	Logger               *slog.Logger         `json:"-" yaml:"-" toml:"-"`                                                             // 自定义日志记录器，设置后忽略 LogHandler 和 LogLevel
+	LogHandler           slog.Handler         `json:"-" yaml:"-" toml:"-"`                                                             // 自定义日志处理器，设置后忽略 LogLevel
+	DialContext          DialFunc             `json:"-" yaml:"-" toml:"-"`                                                             // 自定义建立连接的方式，设置后忽略 KeepAlive
+	Interceptors         []UnaryInterceptor   `json:"-" yaml:"-" toml:"-"`                                                             // 调用拦截器，在默认的日志拦截器及熔断器之后按顺序执行
+}
+
+// ReconnectOption 断线重连配置
+type ReconnectOption struct {
+	Disabled bool    `json:"disabled" yaml:"disabled" toml:"disabled"` // 是否禁用自动重连
+	Backoff  Backoff `json:"backoff" yaml:"backoff" toml:"backoff"`    // 重连间隔
 }
 
 var defaultOption = Option{
-	Network:  "tcp",
-	Codec:    JsonCodec,
-	LogLevel: "debug",
+	Network:              "tcp",
+	Codec:                JsonCodec,
+	LogLevel:             "debug",
+	TimeoutOverhead:      1,
+	DialTimeout:          10,
+	CompressionThreshold: 1024,
+	Reconnect: ReconnectOption{
+		Backoff: defaultBackoff,
+	},
+	Retry: RetryOption{
+		MaxAttempts: 3,
+		Backoff:     defaultBackoff,
+	},
 	SensitiveWords: []string{
 		"key",
 		"app_key",
@@ -34,3 +93,92 @@ var defaultOption = Option{
 		"password",
 	},
 }
+
+// Validate 检查配置是否有效，返回所有无效配置的错误
+func (o *Option) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("rpclient: "+format, args...))
+		}
+	}
+
+	check(o.Network == "" || slices.Contains(networks, o.Network), "unsupported network %q, supported networks are %q", o.Network, networks)
+	check(o.Codec == "" || slices.Contains(codecs, o.Codec), "unsupported codec %q, supported codecs are %q", o.Codec, codecs)
+	check(o.LogLevel == "" || slices.Contains(logLevels, o.LogLevel), "unsupported log level %q, supported levels are %q", o.LogLevel, logLevels)
+	check(o.LogMaxBytes >= 0, "log_max_bytes must not be negative")
+	check(o.LogMaxItems >= 0, "log_max_items must not be negative")
+	check(o.LogSampleRate >= 0 && o.LogSampleRate <= 1, "log_sample_rate must be between 0 and 1, got %v", o.LogSampleRate)
+	check(o.Timeout >= 0, "timeout must not be negative")
+	check(o.TimeoutOverhead >= 0, "timeout_overhead must not be negative")
+	check(o.Retry.MaxAttempts >= 0, "retry.max_attempts must not be negative")
+	check(o.CircuitBreaker.Threshold >= 0, "circuit_breaker.threshold must not be negative")
+	check(o.CircuitBreaker.Cooldown >= 0, "circuit_breaker.cooldown must not be negative")
+
+	if o.Compression != "" {
+		_, ok := compressAlgorithms[o.Compression]
+		check(ok, "unsupported compression %q", o.Compression)
+	}
+	if o.Codec == GoridgeCodec {
+		if _, err := o.Goridge.flags(); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		check(o.Goridge.PayloadCodec == "", "goridge options require the %q codec", GoridgeCodec)
+	}
+	if o.TLS.Enabled && o.TLS.Config == nil {
+		if o.TLS.MinVersion != "" {
+			_, ok := tlsVersions[o.TLS.MinVersion]
+			check(ok, "unsupported TLS version %q", o.TLS.MinVersion)
+		}
+		check((o.TLS.CertFile == "") == (o.TLS.KeyFile == ""), "tls.cert_file and tls.key_file must be set together")
+		check(o.Network != "unix" || o.TLS.ServerName != "" || o.TLS.InsecureSkipVerify, "TLS over unix socket requires tls.server_name")
+	}
+	for _, item := range []struct {
+		name    string
+		backoff Backoff
+	}{{"reconnect.backoff", o.Reconnect.Backoff}, {"retry.backoff", o.Retry.Backoff}} {
+		name, b := item.name, item.backoff
+		check(b.Initial >= 0 && b.Max >= 0, "%s: initial and max must not be negative", name)
+		check(b.Max == 0 || b.Initial <= b.Max, "%s: initial %d exceeds max %d", name, b.Initial, b.Max)
+		check(b.Multiplier == 0 || b.Multiplier >= 1, "%s: multiplier must be at least 1, got %v", name, b.Multiplier)
+		check(b.Jitter >= 0 && b.Jitter <= 1, "%s: jitter must be between 0 and 1, got %v", name, b.Jitter)
+	}
+	return errors.Join(errs...)
+}
+
+// withDefaults 返回 Network、Codec、LogLevel 为空及 TimeoutOverhead、DialTimeout、CompressionThreshold 为 0 时填充默认值后的深拷贝副本
+func (o *Option) withDefaults() *Option {
+	opt := o.clone()
+	if opt.Network == "" {
+		opt.Network = defaultOption.Network
+	}
+	if opt.Codec == "" {
+		opt.Codec = defaultOption.Codec
+	}
+	if opt.LogLevel == "" {
+		opt.LogLevel = defaultOption.LogLevel
+	}
+	if opt.TimeoutOverhead == 0 {
+		opt.TimeoutOverhead = defaultOption.TimeoutOverhead
+	}
+	if opt.DialTimeout == 0 {
+		opt.DialTimeout = defaultOption.DialTimeout
+	}
+	if opt.CompressionThreshold == 0 {
+		opt.CompressionThreshold = defaultOption.CompressionThreshold
+	}
+	return opt
+}
+
+// clone 返回深拷贝的副本，避免共享 slice、map 等可变字段
+func (o *Option) clone() *Option {
+	opt := *o
+	opt.SensitiveWords = slices.Clone(o.SensitiveWords)
+	opt.SensitiveDetectors = slices.Clone(o.SensitiveDetectors)
+	opt.MaskStrategies = maps.Clone(o.MaskStrategies)
+	opt.Retry.Idempotent = slices.Clone(o.Retry.Idempotent)
+	opt.Interceptors = slices.Clone(o.Interceptors)
+	opt.CircuitBreaker.ServiceMethods = slices.Clone(o.CircuitBreaker.ServiceMethods)
+	return &opt
+}
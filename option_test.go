@@ -1,6 +1,10 @@
 package rpclient
 
 import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -102,6 +106,28 @@ func TestOptionWithEmptyValues(t *testing.T) {
 	}
 }
 
+func TestOptionWithDefaults(t *testing.T) {
+	option := (&Option{Codec: MsgpackCodec}).withDefaults()
+	if option.DialTimeout != defaultOption.DialTimeout {
+		t.Errorf("Expected DialTimeout to be %d, got %d", defaultOption.DialTimeout, option.DialTimeout)
+	}
+	if option.CompressionThreshold != defaultOption.CompressionThreshold {
+		t.Errorf("Expected CompressionThreshold to be %d, got %d", defaultOption.CompressionThreshold, option.CompressionThreshold)
+	}
+
+	// 负数表示不限制、全部压缩，不被默认值覆盖
+	option = (&Option{DialTimeout: -1, CompressionThreshold: -1}).withDefaults()
+	if option.DialTimeout != -1 {
+		t.Errorf("Expected DialTimeout to be -1, got %d", option.DialTimeout)
+	}
+	if option.CompressionThreshold != -1 {
+		t.Errorf("Expected CompressionThreshold to be -1, got %d", option.CompressionThreshold)
+	}
+	if err := option.Validate(); err != nil {
+		t.Errorf("Expected negative DialTimeout and CompressionThreshold to be valid, got %v", err)
+	}
+}
+
 func TestOptionWithNilSensitiveWords(t *testing.T) {
 	// Test Option with nil SensitiveWords
 	option := Option{
@@ -118,7 +144,7 @@ func TestOptionWithNilSensitiveWords(t *testing.T) {
 
 func TestOptionCodecTypes(t *testing.T) {
 	// Test Option with different codec types
-	codecs := []string{JsonCodec, GoridgeCodec}
+	codecs := []string{JsonCodec, GoridgeCodec, MsgpackCodec}
 
 	for _, codec := range codecs {
 		option := Option{
@@ -198,3 +224,82 @@ func TestOptionSensitiveWordsModification(t *testing.T) {
 		t.Errorf("Expected second sensitive word to be 'another_secret', got %s", option.SensitiveWords[1])
 	}
 }
+
+func TestOptionValidate(t *testing.T) {
+	valid := []Option{
+		{},
+		defaultOption,
+		{Network: "unix", Codec: GoridgeCodec, LogLevel: "warn", Goridge: GoridgeOption{PayloadCodec: GoridgeJson}},
+		{Network: "tcp6", Codec: MsgpackCodec, LogSampleRate: 1, Compression: GzipCompression},
+		{Network: "unix", TLS: TLSOption{Enabled: true, ServerName: "rpc.local", MinVersion: "1.3"}},
+	}
+	for _, option := range valid {
+		if err := option.Validate(); err != nil {
+			t.Errorf("Expected option %+v to be valid, got %v", option, err)
+		}
+	}
+
+	invalid := []struct {
+		option Option
+		err    string
+	}{
+		{Option{Network: "udp"}, `unsupported network "udp"`},
+		{Option{Network: "unixgram"}, `unsupported network "unixgram"`},
+		{Option{Codec: "xml"}, `unsupported codec "xml"`},
+		{Option{LogLevel: "trace"}, `unsupported log level "trace"`},
+		{Option{LogSampleRate: 1.5}, "log_sample_rate must be between 0 and 1"},
+		{Option{LogMaxItems: -1}, "log_max_items must not be negative"},
+		{Option{Timeout: -1}, "timeout must not be negative"},
+		{Option{Compression: "zstd"}, `unsupported compression "zstd"`},
+		{Option{Codec: JsonCodec, Goridge: GoridgeOption{PayloadCodec: GoridgeJson}}, `goridge options require the "goridge" codec`},
+		{Option{Codec: GoridgeCodec, Goridge: GoridgeOption{PayloadCodec: "xml"}}, `unknown goridge payload codec "xml"`},
+		{Option{TLS: TLSOption{Enabled: true, MinVersion: "1.4"}}, `unsupported TLS version "1.4"`},
+		{Option{TLS: TLSOption{Enabled: true, CertFile: "client.pem"}}, "tls.cert_file and tls.key_file must be set together"},
+		{Option{Network: "unix", TLS: TLSOption{Enabled: true}}, "TLS over unix socket requires tls.server_name"},
+		{Option{Retry: RetryOption{Backoff: Backoff{Initial: 200, Max: 100}}}, "retry.backoff: initial 200 exceeds max 100"},
+		{Option{Reconnect: ReconnectOption{Backoff: Backoff{Jitter: 2}}}, "reconnect.backoff: jitter must be between 0 and 1"},
+		{Option{CircuitBreaker: CircuitBreakerOption{Cooldown: -1}}, "circuit_breaker.cooldown must not be negative"},
+	}
+	for _, tt := range invalid {
+		err := tt.option.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Expected error containing %q, got %v", tt.err, err)
+		}
+	}
+
+	// 返回所有无效配置
+	err := (&Option{Network: "udp", Codec: "xml"}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "network") || !strings.Contains(err.Error(), "codec") {
+		t.Errorf("Expected both network and codec errors, got %v", err)
+	}
+
+	if _, err = NewClient("127.0.0.1:6001", &Option{Network: "udp"}); err == nil {
+		t.Errorf("Expected NewClient to refuse invalid option")
+	}
+}
+
+func TestOptionUnixSocket(t *testing.T) {
+	// unix socket 路径长度有限制，不使用 t.TempDir()
+	dir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, codec := range codecs {
+		ln, err := net.Listen("unix", filepath.Join(dir, codec+".sock"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts := serveTestServer(t, ln, serverCodecs[codec])
+		client := newTestClient(t, ts.addr, &Option{Network: "unix", Codec: codec, LogLevel: "error"})
+		var r Reply
+		if err = client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), "unix")), &r); err != nil {
+			t.Errorf("%s: unexpected error %v", codec, err)
+			continue
+		}
+		if len(r.Results) != 1 || r.Results[0].Data != "unix" {
+			t.Errorf("%s: unexpected reply %+v", codec, r)
+		}
+	}
+}
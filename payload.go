@@ -1,6 +1,7 @@
 package rpclient
 
 import (
+	"log/slog"
 	"slices"
 	"strings"
 )
@@ -38,3 +39,11 @@ func (p *Payload) SetBody(body ...any) *Payload {
 	}
 	return p
 }
+
+// LogValue 实现 slog.LogValuer，记录日志时对 Store.Configuration 及 Body 脱敏
+// 敏感词使用默认配置，Body 中的 struct 同时支持 `rpclient:"sensitive"`、`mask` 标签
+func (p Payload) LogValue() slog.Value {
+	// 转换为不实现 LogValuer 的类型，避免被 slog 再次解析
+	type redactedPayload Payload
+	return slog.AnyValue(redactedPayload(defaultRedactor().RedactPayload(&p)))
+}
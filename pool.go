@@ -0,0 +1,430 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+)
+
+const (
+	RoundRobin   = "round_robin"   // 轮询
+	LeastPending = "least_pending" // 最少未完成调用
+)
+
+// poolReapInterval 连接池检查空闲及过期连接的间隔
+const poolReapInterval = time.Second
+
+// PoolOption 连接池配置
+type PoolOption struct {
+	MinConns    int    `json:"min_conns" yaml:"min_conns" toml:"min_conns"`          // 最小连接数
+	MaxConns    int    `json:"max_conns" yaml:"max_conns" toml:"max_conns"`          // 最大连接数
+	IdleTimeout int    `json:"idle_timeout" yaml:"idle_timeout" toml:"idle_timeout"` // 连接空闲超过该时间（秒）后关闭，0 表示不关闭
+	MaxLifetime int    `json:"max_lifetime" yaml:"max_lifetime" toml:"max_lifetime"` // 连接最长存活时间（秒），0 表示不限制
+	Strategy    string `json:"strategy" yaml:"strategy" toml:"strategy"`             // 连接选择策略：round_robin, least_pending
+
+	HealthCheckInterval int    `json:"health_check_interval" yaml:"health_check_interval" toml:"health_check_interval"` // 检查空闲连接是否可用的间隔（秒），0 表示不检查
+	HealthCheckMethod   string `json:"health_check_method" yaml:"health_check_method" toml:"health_check_method"`       // 检查时调用的服务方法，默认 rpclient.HealthCheck
+}
+
+var defaultPoolOption = PoolOption{
+	MinConns:    1,
+	MaxConns:    4,
+	IdleTimeout: 300,
+	MaxLifetime: 0,
+	Strategy:    RoundRobin,
+
+	HealthCheckInterval: 30,
+	HealthCheckMethod:   "rpclient.HealthCheck",
+}
+
+// poolConn 连接池中的连接
+type poolConn struct {
+	client    *RpcClient
+	createdAt time.Time
+	lastUsed  time.Time // 由 Pool.mu 保护
+	pending   atomic.Int64
+}
+
+// Pool 连接池
+//
+// 连接池维护多个到同一地址的 RpcClient，按 PoolOption.Strategy 分配调用，
+// 连接断开（rpc.ErrShutdown、io.EOF）或健康检查失败时将其移出连接池。
+type Pool struct {
+	addr       string
+	option     *Option
+	poolOption *PoolOption
+	logger     *slog.Logger
+	breaker    *CircuitBreaker // 所有连接共享的熔断器，为 nil 时不熔断
+
+	mu          sync.Mutex
+	changed     chan struct{} // 连接建立、连接池关闭时关闭并重新创建，用于等待正在建立的连接
+	conns       []*poolConn
+	dialing     int
+	next        int
+	closed      bool
+	done        chan struct{}
+	lastChecked time.Time // 上次健康检查的时间，仅由 reapLoop 使用
+}
+
+// NewPool creates a new connection pool to the given address.
+//
+// The opt parameter is passed to NewClient for every connection. If poolOpt is
+// nil, the defaultPoolOption is used. PoolOption.MinConns connections are
+// dialed up front; more are dialed on demand, up to PoolOption.MaxConns, when
+// every existing connection has calls in flight.
+//
+// When PoolOption.HealthCheckInterval is set, idle connections are probed by
+// calling PoolOption.HealthCheckMethod. Any answer from the server, including
+// an error such as an unknown method, counts as healthy; connections that fail
+// to answer are evicted.
+func NewPool(addr string, opt *Option, poolOpt *PoolOption) (*Pool, error) {
+	const op = rrse.Op("new_pool")
+	if opt == nil {
+		opt = &defaultOption
+	}
+	if poolOpt == nil {
+		poolOpt = &defaultPoolOption
+	}
+	poolOption := *poolOpt
+	poolOpt = &poolOption
+	switch poolOpt.Strategy {
+	case "":
+		poolOpt.Strategy = RoundRobin
+	case RoundRobin, LeastPending:
+	default:
+		return nil, rrse.E(op, fmt.Errorf("rpclient: unsupported pool strategy %q", poolOpt.Strategy))
+	}
+	if poolOpt.HealthCheckInterval < 0 {
+		return nil, rrse.E(op, errors.New("rpclient: health_check_interval must not be negative"))
+	}
+	if poolOpt.HealthCheckMethod == "" {
+		poolOpt.HealthCheckMethod = defaultPoolOption.HealthCheckMethod
+	}
+	if poolOpt.MaxConns < 1 {
+		poolOpt.MaxConns = 1
+	}
+	if poolOpt.MinConns > poolOpt.MaxConns {
+		poolOpt.MinConns = poolOpt.MaxConns
+	}
+	p := &Pool{
+		addr:       addr,
+		option:     opt,
+		poolOption: poolOpt,
+		logger:     newLogger(addr, opt).With("pool", true),
+		done:       make(chan struct{}),
+		changed:    make(chan struct{}),
+	}
+	p.lastChecked = time.Now()
+	if opt.CircuitBreaker.Enabled {
+		p.breaker = newCircuitBreaker(opt.CircuitBreaker, p.logger)
+	}
+	for i := 0; i < poolOpt.MinConns; i++ {
+		pc, err := p.dial(context.Background())
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, pc)
+	}
+	go p.reapLoop()
+	return p, nil
+}
+
+// dial 建立新连接，同时受 ctx 及 Option.DialTimeout 限制
+func (p *Pool) dial(ctx context.Context) (*poolConn, error) {
+	// 断开的连接由连接池移除，不需要自动重连；熔断由连接池统一处理
+	opt := *p.option
+	opt.Reconnect.Disabled = true
+	opt.CircuitBreaker.Enabled = false
+	client, err := newClient(ctx, p.addr, &opt)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &poolConn{client: client, createdAt: now, lastUsed: now}, nil
+}
+
+// pick 按策略选择一个连接，调用方需持有 p.mu
+func (p *Pool) pick() *poolConn {
+	if len(p.conns) == 0 {
+		return nil
+	}
+	if p.poolOption.Strategy == LeastPending {
+		best := p.conns[0]
+		for _, pc := range p.conns[1:] {
+			if pc.pending.Load() < best.pending.Load() {
+				best = pc
+			}
+		}
+		return best
+	}
+	pc := p.conns[p.next%len(p.conns)]
+	p.next++
+	return pc
+}
+
+// acquire 获取一个连接，所选连接繁忙且未达到最大连接数时新建连接
+// 没有可用连接且已达到最大连接数时，等待正在建立的连接完成
+// 建立及等待连接均受 ctx 限制
+func (p *Pool) acquire(ctx context.Context) (*poolConn, error) {
+	const op = rrse.Op("pool_acquire")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if p.closed {
+			return nil, rrse.E(op, ErrPoolClosed)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, opContextError(op, err)
+		}
+		pc := p.pick()
+		if (pc == nil || pc.pending.Load() > 0) && len(p.conns)+p.dialing < p.poolOption.MaxConns {
+			p.dialing++
+			p.mu.Unlock()
+			npc, err := p.dial(ctx)
+			p.mu.Lock()
+			p.dialing--
+			p.broadcast()
+			switch {
+			case err != nil && pc == nil:
+				return nil, err
+			case err != nil:
+				p.logger.Warn("Dial", "error", err)
+			case p.closed:
+				_ = npc.client.Close()
+				return nil, rrse.E(op, ErrPoolClosed)
+			default:
+				p.conns = append(p.conns, npc)
+				pc = npc
+			}
+		}
+		if pc != nil {
+			pc.pending.Add(1)
+			pc.lastUsed = time.Now()
+			return pc, nil
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+		p.mu.Lock()
+	}
+}
+
+// broadcast 唤醒所有等待连接的调用，调用方需持有 p.mu
+func (p *Pool) broadcast() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// opContextError 连接池等待连接、集群转移调用时 ctx 结束的错误，超过截止时间时为 rrse.TimeOut 类型
+func opContextError(op rrse.Op, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return rrse.E(op, rrse.TimeOut, err)
+	}
+	return rrse.E(op, err)
+}
+
+// release 归还连接，连接已断开时将其移出连接池
+func (p *Pool) release(pc *poolConn, err error) {
+	pc.pending.Add(-1)
+	if !isConnError(err) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.remove(pc) {
+		p.logger.Warn("Evict", "reason", "broken", "error", err)
+		_ = pc.client.Close()
+	}
+}
+
+// remove 将连接移出连接池，调用方需持有 p.mu
+func (p *Pool) remove(pc *poolConn) bool {
+	for i, v := range p.conns {
+		if v == pc {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) reapLoop() {
+	ticker := time.NewTicker(poolReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			if interval := time.Duration(p.poolOption.HealthCheckInterval) * time.Second; interval > 0 && now.Sub(p.lastChecked) >= interval {
+				p.lastChecked = now
+				p.healthCheck(interval)
+			}
+			p.reap(now)
+		}
+	}
+}
+
+// healthCheck 检查空闲连接，在 timeout 内没有响应的连接被移出连接池
+func (p *Pool) healthCheck(timeout time.Duration) {
+	p.mu.Lock()
+	var idle []*poolConn
+	for _, pc := range p.conns {
+		if pc.pending.Load() == 0 {
+			// 检查期间计为未完成的调用，避免被 reap 关闭及 least_pending 选中
+			pc.pending.Add(1)
+			idle = append(idle, pc)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := pc.client.ping(ctx, p.poolOption.HealthCheckMethod)
+		cancel()
+		pc.pending.Add(-1)
+		if err == nil {
+			continue
+		}
+		p.mu.Lock()
+		if p.remove(pc) {
+			p.logger.Warn("Evict", "reason", "unhealthy", "error", err)
+			_ = pc.client.Close()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// reap 关闭空闲超时及超过最长存活时间的连接，并补足最小连接数
+func (p *Pool) reap(now time.Time) {
+	idleTimeout := time.Duration(p.poolOption.IdleTimeout) * time.Second
+	maxLifetime := time.Duration(p.poolOption.MaxLifetime) * time.Second
+
+	p.mu.Lock()
+	var evicted []*poolConn
+	for _, pc := range append([]*poolConn{}, p.conns...) {
+		if pc.pending.Load() > 0 {
+			continue
+		}
+		var reason string
+		switch {
+		case maxLifetime > 0 && now.Sub(pc.createdAt) >= maxLifetime:
+			reason = "lifetime"
+		case idleTimeout > 0 && now.Sub(pc.lastUsed) >= idleTimeout && len(p.conns) > p.poolOption.MinConns:
+			reason = "idle"
+		default:
+			continue
+		}
+		p.remove(pc)
+		evicted = append(evicted, pc)
+		p.logger.Debug("Evict", "reason", reason)
+	}
+	missing := p.poolOption.MinConns - len(p.conns) - p.dialing
+	closed := p.closed
+	p.mu.Unlock()
+
+	for _, pc := range evicted {
+		_ = pc.client.Close()
+	}
+	for i := 0; i < missing && !closed; i++ {
+		pc, err := p.dial(context.Background())
+		if err != nil {
+			p.logger.Warn("Dial", "error", err)
+			return
+		}
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			_ = pc.client.Close()
+			return
+		}
+		p.conns = append(p.conns, pc)
+		p.broadcast()
+		p.mu.Unlock()
+	}
+}
+
+// Call calls the RPC server through one of the pooled connections.
+func (p *Pool) Call(serviceMethod string, args Args, reply *Reply) error {
+	return p.CallContext(context.Background(), serviceMethod, args, reply)
+}
+
+// CallContext is like Call but honours the cancellation and deadline of ctx.
+//
+// When Option.CircuitBreaker is enabled, the circuit breaker is shared by every
+// pooled connection.
+func (p *Pool) CallContext(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	if p.breaker != nil {
+		return p.breaker.Intercept(ctx, serviceMethod, args, reply, p.invoke)
+	}
+	return p.invoke(ctx, serviceMethod, args, reply)
+}
+
+// invoke 通过一个连接发起调用
+func (p *Pool) invoke(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	pc, err := p.acquire(ctx)
+	if err != nil {
+		reply.Reset()
+		return err
+	}
+	err = pc.client.CallContext(ctx, serviceMethod, args, reply)
+	p.release(pc, err)
+	return err
+}
+
+// Len 当前连接数
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// Close closes every pooled connection. Calls made after Close return
+// ErrPoolClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.broadcast()
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, pc := range conns {
+		if err := pc.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return rrse.E(rrse.Op("pool_close"), errors.Join(errs...))
+	}
+	return nil
+}
+
+// ping 调用 serviceMethod 检查连接是否可用，不经过拦截器及重试
+// 服务端返回的错误（如方法不存在）说明连接可用，不视为失败
+func (c *RpcClient) ping(ctx context.Context, serviceMethod string) error {
+	err := c.invoke(ctx, serviceMethod, Args{}, new(Reply))
+	var serverErr rpc.ServerError
+	if err == nil || errors.As(cause(err), &serverErr) {
+		return nil
+	}
+	return err
+}
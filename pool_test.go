@@ -0,0 +1,163 @@
+package rpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool_Call(t *testing.T) {
+	ts := newTestServer(t)
+	pool, err := NewPool(ts.addr, &testOption, &PoolOption{MinConns: 1, MaxConns: 3, Strategy: LeastPending})
+	assert.NoError(t, err)
+	defer pool.Close()
+	assert.Equal(t, 1, pool.Len())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r Reply
+			err := pool.Call("Test.Sleep", NewArgs().Add(NewPayload(testStore("1"), 100)), &r)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(r.Results))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, pool.Len())
+
+	assert.NoError(t, pool.Close())
+	var r Reply
+	assert.ErrorIs(t, cause(pool.Call("Test.Echo", NewArgs(), &r)), ErrPoolClosed)
+}
+
+func TestPool_EvictBroken(t *testing.T) {
+	ts := newTestServer(t)
+	pool, err := NewPool(ts.addr, &testOption, &PoolOption{MinConns: 2, MaxConns: 2})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+	var r Reply
+	for i := 0; i < 2; i++ {
+		err = pool.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+		assert.True(t, isConnError(err))
+	}
+	assert.Equal(t, 0, pool.Len())
+
+	// 连接被移出后按需重新建立
+	err = pool.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestPool_reap(t *testing.T) {
+	ts := newTestServer(t)
+	pool, err := NewPool(ts.addr, &testOption, &PoolOption{MinConns: 1, MaxConns: 3, IdleTimeout: 10, MaxLifetime: 60})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 2; i++ {
+		pc, err := pool.dial(context.Background())
+		assert.NoError(t, err)
+		pool.conns = append(pool.conns, pc)
+	}
+	assert.Equal(t, 3, pool.Len())
+
+	// 空闲超时的连接被关闭，但保留最小连接数
+	pool.reap(time.Now().Add(20 * time.Second))
+	assert.Equal(t, 1, pool.Len())
+
+	// 超过最长存活时间的连接被替换
+	old := pool.conns[0]
+	pool.reap(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, 1, pool.Len())
+	assert.NotSame(t, old, pool.conns[0])
+}
+
+func TestPool_AcquireWaitsForDial(t *testing.T) {
+	ts := newTestServer(t)
+	pool, err := NewPool(ts.addr, &testOption, &PoolOption{MinConns: 0, MaxConns: 1})
+	assert.NoError(t, err)
+	defer pool.Close()
+	assert.Equal(t, 0, pool.Len())
+
+	// 没有连接时，并发的调用等待正在建立的连接
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r Reply
+			assert.NoError(t, pool.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestPool_AcquireContext(t *testing.T) {
+	// 模拟无响应的主机：建立连接一直阻塞到 ctx 结束
+	opt := testOption
+	opt.DialTimeout = -1
+	opt.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	pool, err := NewPool("127.0.0.1:0", &opt, &PoolOption{MinConns: 0, MaxConns: 1})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	// 建立连接及等待其他调用建立连接都受调用方截止时间的限制
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			var r Reply
+			err := pool.CallContext(ctx, "Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+			assert.Error(t, err)
+			assert.Less(t, time.Since(start), time.Second)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, pool.Len())
+}
+
+func TestPool_HealthCheck(t *testing.T) {
+	ts := newTestServer(t)
+	pool, err := NewPool(ts.addr, &testOption, &PoolOption{MinConns: 0, MaxConns: 2, HealthCheckInterval: 1})
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	var r Reply
+	assert.NoError(t, pool.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r))
+	assert.Equal(t, 1, pool.Len())
+
+	// 服务端未注册健康检查的服务方法时，返回的错误也说明连接可用
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, 1, pool.Len())
+
+	// 断开的空闲连接在健康检查时被移出，不需要等到下一次调用失败
+	ts.closeConns()
+	assert.Eventually(t, func() bool {
+		return pool.Len() == 0
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestNewPool_InvalidOption(t *testing.T) {
+	_, err := NewPool("127.0.0.1:0", &testOption, &PoolOption{MaxConns: 1, Strategy: "random"})
+	assert.ErrorContains(t, err, `unsupported pool strategy "random"`)
+	_, err = NewPool("127.0.0.1:0", &testOption, &PoolOption{MaxConns: 1, HealthCheckInterval: -1})
+	assert.ErrorContains(t, err, "health_check_interval must not be negative")
+}
@@ -0,0 +1,123 @@
+package rpclient
+
+import (
+	"context"
+	"net/rpc"
+	"time"
+)
+
+// State 客户端连接状态
+type State int
+
+const (
+	StateConnecting State = iota // 连接中
+	StateReady                   // 已连接
+	StateClosed                  // 已关闭
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReady:
+		return "ready"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// State 当前连接状态
+func (c *RpcClient) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// readyClient 返回可用的 rpc.Client，正在重连时等待重连完成，ctx 结束时返回 ErrNotReady
+// ctx 没有截止时间时不等待，直接返回 ErrNotReady，避免服务端不可用时调用无限期阻塞
+func (c *RpcClient) readyClient(ctx context.Context) (*rpc.Client, error) {
+	for {
+		c.mu.RLock()
+		state, client, ready := c.state, c.client, c.ready
+		c.mu.RUnlock()
+		switch state {
+		case StateReady:
+			return client, nil
+		case StateClosed:
+			return nil, ErrClosed
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, ErrNotReady
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ErrNotReady
+		}
+	}
+}
+
+// Client 返回当前使用的 rpc.Client
+//
+// Deprecated: RpcClient 不再内嵌 *rpc.Client，原来的 c.Client.Call 需要改为 c.Client().Call。
+// 重连时 rpc.Client 会被替换，返回值只在下一次重连前有效，正在重连时返回已断开的 rpc.Client，
+// 其调用返回 rpc.ErrShutdown。请使用 CallContext，参数不是 Args 时使用 CallRaw。
+func (c *RpcClient) Client() *rpc.Client {
+	if client, err := c.readyClient(context.Background()); err == nil {
+		return client
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// reconnect 在 broken 连接断开后开始后台重连
+// 同一连接的多个失败调用只会触发一次重连
+func (c *RpcClient) reconnect(broken *rpc.Client) {
+	if c.option.Reconnect.Disabled {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != StateReady || c.client != broken {
+		return
+	}
+	c.state = StateConnecting
+	c.ready = make(chan struct{})
+	go c.redial(broken)
+}
+
+// redial 按指数退避不断重连，直到成功或客户端被关闭
+func (c *RpcClient) redial(broken *rpc.Client) {
+	for attempt := 0; ; attempt++ {
+		wait := c.option.Reconnect.Backoff.Duration(attempt)
+		c.logger.Warn("Reconnect", "attempt", attempt+1, "wait", wait.String())
+		select {
+		case <-c.done:
+			return
+		case <-time.After(wait):
+		}
+
+		client, err := c.dial(context.Background())
+		if err != nil {
+			c.logger.Error("Reconnect", "attempt", attempt+1, "error", err)
+			continue
+		}
+
+		c.mu.Lock()
+		if c.state == StateClosed {
+			c.mu.Unlock()
+			_ = client.Close()
+			return
+		}
+		c.client = client
+		c.state = StateReady
+		close(c.ready)
+		c.mu.Unlock()
+		_ = broken.Close()
+		c.logger.Info("Reconnect", "attempt", attempt+1, "error", nil)
+		return
+	}
+}
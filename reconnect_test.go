@@ -0,0 +1,129 @@
+package rpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitState 等待客户端进入指定状态
+func waitState(t *testing.T, c *RpcClient, state State, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for c.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected state %s, got %s", state, c.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRpcClient_Reconnect(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Backoff = Backoff{Initial: 10, Max: 50}
+	client := newTestClient(t, ts.addr, &opt)
+	assert.Equal(t, StateReady, client.State())
+
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+
+	var r Reply
+	err := client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+	assert.True(t, isConnError(err))
+	waitState(t, client, StateReady, 2*time.Second)
+
+	err = client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.Results))
+
+	// 废弃的 Client 返回重连后的 rpc.Client
+	var raw Reply
+	assert.NoError(t, client.Client().Call("Test.Echo", NewArgs().Add(NewPayload(testStore("2"))), &raw))
+	assert.Equal(t, "2", raw.Results[0].StoreId)
+
+	assert.NoError(t, client.Close())
+	assert.Equal(t, StateClosed, client.State())
+	assert.ErrorIs(t, cause(client.Call("Test.Echo", NewArgs(), &r)), ErrClosed)
+}
+
+func TestRpcClient_ReconnectServerDown(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Backoff = Backoff{Initial: 10, Max: 50}
+	client := newTestClient(t, ts.addr, &opt)
+
+	time.Sleep(50 * time.Millisecond)
+	_ = ts.listener.Close()
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+
+	var r Reply
+	err := client.Call("Test.Echo", NewArgs(), &r)
+	assert.True(t, isConnError(err))
+	assert.Equal(t, StateConnecting, client.State())
+
+	// 重连期间的调用等待重连完成，直到 ctx 结束
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = client.CallContext(ctx, "Test.Echo", NewArgs(), &r)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	_, ok := AsTimeoutError(err)
+	assert.True(t, ok, err)
+	assert.Equal(t, StateConnecting, client.State())
+
+	// 没有截止时间的调用不等待重连
+	start = time.Now()
+	err = client.Call("Test.Echo", NewArgs(), &r)
+	assert.ErrorIs(t, cause(err), ErrNotReady)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, StateConnecting, client.State())
+
+	// 关闭客户端会停止重连
+	assert.NoError(t, client.Close())
+	assert.Equal(t, StateClosed, client.State())
+}
+
+func TestRpcClient_ReconnectWait(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Backoff = Backoff{Initial: 200}
+	client := newTestClient(t, ts.addr, &opt)
+
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+
+	var r Reply
+	err := client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+	assert.True(t, isConnError(err))
+	assert.Equal(t, StateConnecting, client.State())
+
+	// 重连期间发起的有截止时间的调用在重连完成后发出
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err = client.CallContext(ctx, "Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.Results))
+	assert.Equal(t, StateReady, client.State())
+}
+
+func TestRpcClient_ReconnectDisabled(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Disabled = true
+	client := newTestClient(t, ts.addr, &opt)
+
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+
+	var r Reply
+	err := client.Call("Test.Echo", NewArgs(), &r)
+	assert.True(t, isConnError(err))
+	assert.Equal(t, StateReady, client.State())
+}
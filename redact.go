@@ -0,0 +1,366 @@
+package rpclient
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// redactMaxDepth 脱敏时遍历嵌套数据的最大深度，超出部分不再输出
+const redactMaxDepth = 32
+
+// builtinDetectors 内置的敏感值识别规则
+var builtinDetectors = map[string]*regexp.Regexp{
+	"email": regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	// 不带分隔符的 10 位数字不视为电话号码，避免误伤商品 ID、SKU ID 等
+	"phone": regexp.MustCompile(strings.Join([]string{
+		`\+\d{1,3}[ .-]?(?:\(\d{1,4}\)[ .-]?)?\d{1,4}(?:[ .-]?\d{2,4}){1,4}\b`, // 国际格式，如 +86 138 1234 5678、+1 (555) 123-4567
+		`\(\d{3}\)[ .-]?\d{3}[ .-]?\d{4}\b`,                                    // 北美格式，如 (555) 123-4567
+		`\b\d{3}[ .-]\d{3}[ .-]\d{4}\b`,                                        // 北美格式，如 555-123-4567、555.123.4567
+		`\b1[3-9]\d[ -]?\d{4}[ -]?\d{4}\b`,                                     // 中国手机号，如 13812345678、138-1234-5678
+	}, "|")),
+	"token": regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+|(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*`),
+}
+
+// Redactor 日志脱敏器
+//
+// 按键名识别敏感数据，支持三种规则：
+//   - 普通单词：不区分大小写完全匹配，如 `access_token`
+//   - 通配符：规则同 path.Match，不区分大小写，如 `*_secret`、`china_*`
+//   - 正则表达式：以 `re:` 开头，不区分大小写，如 `re:^(app|china)_key$`
+//
+// 同时可按值识别敏感数据（detectors），内置 email、phone、token，
+// 也可以使用 `re:` 开头的正则表达式，匹配到的部分会被脱敏。
+//
+// struct 字段还可以通过标签声明为敏感字段，无论键名是否匹配：
+//
+//	Phone   string `json:"phone" rpclient:"sensitive"` // 同 mask:"partial"
+//	Token   string `json:"token" mask:"full"`
+//	OpenId  string `json:"open_id" mask:"hash"`
+//	CardNo  string `json:"card_no" mask:"keep_last:4"`
+//
+// 掩码方式见 SetMaskStrategy，任意类型的值都会被掩码。
+type Redactor struct {
+	words         map[string]Masker
+	patterns      []keyPattern
+	detectors     []*regexp.Regexp
+	defaultMasker Masker
+}
+
+// keyPattern 通配符或正则表达式形式的敏感键规则
+type keyPattern struct {
+	match  func(key string) bool
+	masker Masker // 为 nil 时使用 defaultMasker
+}
+
+// NewRedactor 根据敏感键名规则及敏感值识别规则创建脱敏器
+func NewRedactor(sensitiveWords []string, detectors []string) (*Redactor, error) {
+	r := &Redactor{
+		words:         make(map[string]Masker),
+		defaultMasker: maskString,
+	}
+	for _, word := range sensitiveWords {
+		if err := r.addKey(word, nil, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, detector := range detectors {
+		if re, ok := builtinDetectors[detector]; ok {
+			r.detectors = append(r.detectors, re)
+			continue
+		}
+		if !strings.HasPrefix(detector, "re:") {
+			return nil, fmt.Errorf("rpclient: unknown sensitive detector %q", detector)
+		}
+		re, err := regexp.Compile(strings.TrimPrefix(detector, "re:"))
+		if err != nil {
+			return nil, fmt.Errorf("rpclient: invalid sensitive detector %q: %w", detector, err)
+		}
+		r.detectors = append(r.detectors, re)
+	}
+	return r, nil
+}
+
+// newRedactor 根据 Option 创建脱敏器
+func newRedactor(opt *Option) (*Redactor, error) {
+	r, err := NewRedactor(opt.SensitiveWords, opt.SensitiveDetectors)
+	if err != nil {
+		return nil, err
+	}
+	if opt.DefaultMaskStrategy != "" {
+		if err = r.SetDefaultMaskStrategy(opt.DefaultMaskStrategy); err != nil {
+			return nil, err
+		}
+	}
+	// 按键名倒序添加，使排序靠前的规则优先匹配
+	keys := slices.Sorted(maps.Keys(opt.MaskStrategies))
+	slices.Reverse(keys)
+	for _, key := range keys {
+		if err = r.SetMaskStrategy(key, opt.MaskStrategies[key]); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// addKey 添加敏感键规则，first 为 true 时规则优先于已有规则
+func (r *Redactor) addKey(word string, masker Masker, first bool) error {
+	var pattern keyPattern
+	switch {
+	case strings.HasPrefix(word, "re:"):
+		re, err := regexp.Compile("(?i)" + strings.TrimPrefix(word, "re:"))
+		if err != nil {
+			return fmt.Errorf("rpclient: invalid sensitive word %q: %w", word, err)
+		}
+		pattern = keyPattern{match: re.MatchString, masker: masker}
+	case strings.ContainsAny(word, "*?["):
+		glob := strings.ToLower(word)
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("rpclient: invalid sensitive word %q: %w", word, err)
+		}
+		pattern = keyPattern{match: func(key string) bool {
+			ok, _ := path.Match(glob, key)
+			return ok
+		}, masker: masker}
+	default:
+		if _, ok := r.words[strings.ToLower(word)]; !ok || first {
+			r.words[strings.ToLower(word)] = masker
+		}
+		return nil
+	}
+	if first {
+		r.patterns = append([]keyPattern{pattern}, r.patterns...)
+	} else {
+		r.patterns = append(r.patterns, pattern)
+	}
+	return nil
+}
+
+// SetMaskStrategy 为匹配 key 的敏感键指定掩码方式，key 的规则同敏感词，并自动视为敏感键
+// strategy 可以为 partial、full、hash、length、keep_last:N 或通过 RegisterMasker 注册的名称
+func (r *Redactor) SetMaskStrategy(key, strategy string) error {
+	masker, err := lookupMasker(strategy)
+	if err != nil {
+		return err
+	}
+	return r.addKey(key, masker, true)
+}
+
+// SetDefaultMaskStrategy 设置未单独指定掩码方式的敏感键使用的掩码方式，默认为 partial
+func (r *Redactor) SetDefaultMaskStrategy(strategy string) error {
+	masker, err := lookupMasker(strategy)
+	if err != nil {
+		return err
+	}
+	r.defaultMasker = masker
+	return nil
+}
+
+// keyMasker 返回敏感键使用的掩码方式，非敏感键返回 false
+func (r *Redactor) keyMasker(key string) (Masker, bool) {
+	key = strings.ToLower(key)
+	masker, ok := r.words[key]
+	if !ok {
+		for _, pattern := range r.patterns {
+			if pattern.match(key) {
+				masker, ok = pattern.masker, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	if masker == nil {
+		masker = r.defaultMasker
+	}
+	return masker, true
+}
+
+// IsSensitiveKey 键名是否为敏感键
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	_, ok := r.keyMasker(key)
+	return ok
+}
+
+// Redact 返回脱敏后的副本，不修改 v
+// 嵌套的 map、slice、struct 均会被遍历，struct 按 `json` 标签转换为 map
+func (r *Redactor) Redact(v any) any {
+	return r.redact(reflect.ValueOf(v), nil, 0)
+}
+
+// redact 脱敏 v，masker 不为 nil 时 v 位于敏感键下，其中的每一个标量都会被掩码
+func (r *Redactor) redact(v reflect.Value, masker Masker, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > redactMaxDepth {
+		return "[MAX DEPTH]"
+	}
+
+	// null.String、time.Time 等自定义序列化的类型按其 JSON 形式处理
+	if v.Kind() != reflect.Interface && v.Kind() != reflect.Pointer && v.CanInterface() {
+		switch v.Interface().(type) {
+		case json.Marshaler, encoding.TextMarshaler:
+			b, err := json.Marshal(v.Interface())
+			if err != nil {
+				return r.scalar(fmt.Sprint(v.Interface()), masker)
+			}
+			var decoded any
+			if err = json.Unmarshal(b, &decoded); err != nil {
+				return r.scalar(string(b), masker)
+			}
+			return r.redact(reflect.ValueOf(decoded), masker, depth+1)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return r.redact(v.Elem(), masker, depth+1)
+	case reflect.String:
+		return r.scalar(v.String(), masker)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			m[key] = r.redactField(key, iter.Value(), masker, depth)
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if masker != nil {
+				return masker(string(v.Bytes()))
+			}
+			return v.Interface()
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s[i] = r.redact(v.Index(i), masker, depth+1)
+		}
+		return s
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if fm := fieldMasker(field); fm != nil && masker == nil {
+				m[name] = r.redact(v.Field(i), fm, depth+1)
+				continue
+			}
+			m[name] = r.redactField(name, v.Field(i), masker, depth)
+		}
+		return m
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		if masker != nil {
+			return masker(fmt.Sprint(v.Interface()))
+		}
+		return v.Interface()
+	}
+}
+
+// redactField 脱敏键值对中的值，敏感键的值会被掩码
+func (r *Redactor) redactField(key string, v reflect.Value, masker Masker, depth int) any {
+	if masker == nil {
+		masker, _ = r.keyMasker(key)
+	}
+	return r.redact(v, masker, depth+1)
+}
+
+// scalar 掩码或识别字符串中的敏感值
+func (r *Redactor) scalar(s string, masker Masker) string {
+	if masker != nil {
+		return masker(s)
+	}
+	return r.detect(s)
+}
+
+// fieldMasker 根据 `mask`、`rpclient` 标签返回字段的掩码方式，非敏感字段返回 nil
+// 未知的掩码方式按 partial 处理
+func fieldMasker(field reflect.StructField) Masker {
+	if strategy := field.Tag.Get("mask"); strategy != "" {
+		masker, err := lookupMasker(strategy)
+		if err != nil {
+			return maskString
+		}
+		return masker
+	}
+	for _, opt := range strings.Split(field.Tag.Get("rpclient"), ",") {
+		if opt == "sensitive" {
+			return maskString
+		}
+	}
+	return nil
+}
+
+// RedactPayload 返回 Store.Configuration 及 Body 脱敏后的 Payload 副本
+func (r *Redactor) RedactPayload(p *Payload) Payload {
+	if p == nil {
+		return Payload{}
+	}
+	sanitized := Payload{
+		Store: p.Store,
+		Body:  r.Redact(p.Body),
+	}
+	cfg, _ := r.Redact(p.Store.Configuration).(map[string]any)
+	sanitized.Store.Configuration = cfg
+	return sanitized
+}
+
+// RedactResult 返回 Data 脱敏，Label、Error 中敏感值被识别脱敏后的 Result 副本
+func (r *Redactor) RedactResult(result Result) Result {
+	result.Data = r.Redact(result.Data)
+	if result.Label.Valid {
+		result.Label.String = r.detect(result.Label.String)
+	}
+	if result.Error.Valid {
+		result.Error.String = r.detect(result.Error.String)
+	}
+	return result
+}
+
+// defaultRedactor 使用 defaultOption 中敏感词的脱敏器，用于 Payload、Args 的 LogValue
+var defaultRedactor = sync.OnceValue(func() *Redactor {
+	r, _ := NewRedactor(defaultOption.SensitiveWords, nil)
+	return r
+})
+
+// detect 脱敏字符串中被识别为敏感值的部分
+func (r *Redactor) detect(s string) string {
+	for _, re := range r.detectors {
+		s = re.ReplaceAllStringFunc(s, maskString)
+	}
+	return s
+}
@@ -0,0 +1,259 @@
+package rpclient
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestRedactor_IsSensitiveKey(t *testing.T) {
+	r, err := NewRedactor([]string{"token", "*_secret", "re:^(app|china)_key$"}, nil)
+	assert.NoError(t, err)
+	tests := map[string]bool{
+		"token":            true,
+		"Token":            true,
+		"access_token":     false,
+		"app_secret":       true,
+		"CHINA_APP_SECRET": true,
+		"secret":           false,
+		"app_key":          true,
+		"China_Key":        true,
+		"app_key_id":       false,
+	}
+	for key, want := range tests {
+		assert.Equalf(t, want, r.IsSensitiveKey(key), "%s", key)
+	}
+
+	_, err = NewRedactor([]string{"re:("}, nil)
+	assert.Error(t, err)
+	_, err = NewRedactor([]string{"[a-"}, nil)
+	assert.Error(t, err)
+	_, err = NewRedactor(nil, []string{"unknown"})
+	assert.Error(t, err)
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	type address struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Internal string `json:"-"`
+	}
+	type order struct {
+		OrderSn  string      `json:"order_sn"`
+		Token    string      `json:"token"`
+		Label    null.String `json:"label"`
+		Address  *address    `json:"address"`
+		Contacts []address
+	}
+	r, err := NewRedactor([]string{"token", "password"}, []string{"email", "phone"})
+	assert.NoError(t, err)
+
+	src := map[string]any{
+		"Token": "abcdefghijkl",
+		"nested": map[string]any{
+			"password": 123456,
+			"items":    []any{map[string]any{"token": "xyz"}, "mail me: john.doe@example.com"},
+		},
+		"order": order{
+			OrderSn:  "PO-211-19255520399990061",
+			Token:    "abcdefghijkl",
+			Label:    null.StringFrom("call 13812345678"),
+			Address:  &address{Email: "a@b.io", Password: "secret", Internal: "x"},
+			Contacts: []address{{Email: "c@d.io"}},
+		},
+	}
+	dst := r.Redact(src).(map[string]any)
+	assert.Equal(t, "abc******jkl", dst["Token"])
+	nested := dst["nested"].(map[string]any)
+	assert.Equal(t, "1****6", nested["password"])
+	items := nested["items"].([]any)
+	assert.Equal(t, "x*z", items[0].(map[string]any)["token"])
+	assert.Equal(t, "mail me: john.**********e.com", items[1])
+
+	o := dst["order"].(map[string]any)
+	assert.Equal(t, "PO-211-19255520399990061", o["order_sn"])
+	assert.Equal(t, "abc******jkl", o["token"])
+	assert.Equal(t, "call 138*****678", o["label"])
+	addr := o["address"].(map[string]any)
+	assert.Equal(t, "a****o", addr["email"])
+	assert.Equal(t, "s****t", addr["password"])
+	assert.NotContains(t, addr, "Internal")
+	assert.Equal(t, "c****o", o["Contacts"].([]any)[0].(map[string]any)["email"])
+
+	// 原数据不被修改
+	assert.Equal(t, "abcdefghijkl", src["Token"])
+	assert.Nil(t, r.Redact(nil))
+}
+
+func TestRedactor_DetectPhone(t *testing.T) {
+	r, err := NewRedactor(nil, []string{"phone"})
+	assert.NoError(t, err)
+	tests := map[string]string{
+		"call 13812345678":         "call 138*****678",
+		"call 138-1234-5678":       "call 138*******678",
+		"call +86 138 1234 5678":   "call +86 *********5678",
+		"call +1 555-123-4567":     "call +1 *********567",
+		"call (555) 123-4567":      "call (55********567",
+		"call 555-123-4567":        "call 555******567",
+		"call 555.123.4567":        "call 555******567",
+		"call 5551234567":          "call 5551234567",
+		"goods_id 6012345678":      "goods_id 6012345678",
+		"sku_id=9876543210":        "sku_id=9876543210",
+		"PO-211-19255520399990061": "PO-211-19255520399990061",
+		"2024-01-15 12:30:00":      "2024-01-15 12:30:00",
+	}
+	for s, want := range tests {
+		assert.Equalf(t, want, r.Redact(s), "%s", s)
+	}
+}
+
+func TestRedactor_RedactResult(t *testing.T) {
+	r, err := NewRedactor([]string{"token"}, []string{"email", "phone"})
+	assert.NoError(t, err)
+	result := Result{
+		StoreId: "1",
+		Label:   null.StringFrom("john.doe@example.com"),
+		Error:   null.StringFrom("invalid phone 13812345678"),
+		Data:    map[string]any{"token": "abcdefghijkl"},
+	}
+	sanitized := r.RedactResult(result)
+	assert.Equal(t, "1", sanitized.StoreId)
+	assert.Equal(t, "john.**********e.com", sanitized.Label.String)
+	assert.Equal(t, "invalid phone 138*****678", sanitized.Error.String)
+	assert.Equal(t, "abc******jkl", sanitized.Data.(map[string]any)["token"])
+	assert.Equal(t, "john.doe@example.com", result.Label.String)
+	assert.False(t, r.RedactResult(Result{}).Error.Valid)
+}
+
+func TestRpcClient_logCallRedact(t *testing.T) {
+	ts := newTestServer(t)
+
+	var buf bytes.Buffer
+	opt := testOption
+	opt.SensitiveWords = []string{"*_token", "receiver_*"}
+	opt.SensitiveDetectors = []string{"email"}
+	opt.LogHandler = slog.NewJSONHandler(&buf, nil)
+	client := newTestClient(t, ts.addr, &opt)
+
+	store := testStore("1")
+	store.Configuration["access_token"] = "token-value-123456"
+	body := map[string]any{
+		"receiver_name": "John Doe",
+		"buyer":         map[string]any{"email": "john.doe@example.com"},
+	}
+	var r Reply
+	assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(store, body)), &r))
+	assert.NotContains(t, buf.String(), "token-value-123456")
+	assert.NotContains(t, buf.String(), "John Doe")
+	assert.NotContains(t, buf.String(), "john.doe@example.com")
+	// 返回给调用方的数据不受影响
+	assert.Equal(t, "John Doe", r.Results[0].Data.(map[string]any)["receiver_name"])
+}
+
+func TestMaskString(t *testing.T) {
+	tests := map[string]string{
+		"":               "",
+		"ab":             "**",
+		"abc":            "a*c",
+		"123456":         "1****6",
+		"abcdefghijkl":   "abc******jkl",
+		"abcdefghijklmn": "abc********lmn",
+		"张三":             "**",
+		"张三丰":            "张*丰",
+		"上海市浦东新区世纪大道":    "上海市*****纪大道",
+	}
+	for s, want := range tests {
+		assert.Equalf(t, want, maskString(s), "%s", s)
+		assert.True(t, utf8.ValidString(maskString(s)))
+	}
+}
+
+func TestRedactor_StructTag(t *testing.T) {
+	type receiver struct {
+		Name    string `json:"receiver"`
+		Phone   string `json:"mobile" rpclient:"sensitive"`
+		Address string `json:"address" mask:"full"`
+		OpenId  string `json:"open_id" mask:"hash"`
+		Zip     int    `json:"zip" mask:"partial"`
+	}
+	r, err := NewRedactor(nil, nil)
+	assert.NoError(t, err)
+	dst := r.Redact(receiver{
+		Name:    "John",
+		Phone:   "13812345678",
+		Address: "1 Infinite Loop",
+		OpenId:  "o-123",
+		Zip:     95014,
+	}).(map[string]any)
+	assert.Equal(t, "John", dst["receiver"])
+	assert.Equal(t, "138*****678", dst["mobile"])
+	assert.Equal(t, "******", dst["address"])
+	assert.Equal(t, maskHash("o-123"), dst["open_id"])
+	assert.Equal(t, "sha256:", dst["open_id"].(string)[:7])
+	assert.Equal(t, "9***4", dst["zip"])
+}
+
+func TestPayload_LogValue(t *testing.T) {
+	type body struct {
+		OrderSn string `json:"order_sn"`
+		Phone   string `json:"phone" rpclient:"sensitive"`
+	}
+	store := testStore("1")
+	store.Configuration["app_secret"] = "secret-value-123456"
+	p := NewPayload(store, body{OrderSn: "PO-1", Phone: "13812345678"})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("payload", "payload", p, "args", NewArgs().Add(p))
+	assert.NotContains(t, buf.String(), "secret-value-123456")
+	assert.NotContains(t, buf.String(), "13812345678")
+	assert.Contains(t, buf.String(), `"order_sn":"PO-1"`)
+	assert.Contains(t, buf.String(), `"payload":{"store":{"id":"1"`)
+	assert.Contains(t, buf.String(), `"args":[{"store":{"id":"1"`)
+}
+
+func TestRedactor_MaskStrategies(t *testing.T) {
+	r, err := newRedactor(&Option{
+		SensitiveWords:      []string{"token", "secret", "rate", "enabled", "profile"},
+		DefaultMaskStrategy: MaskFull,
+		MaskStrategies: map[string]string{
+			"card_*": "keep_last:4",
+			"token":  MaskHash,
+			"phone":  MaskLength,
+		},
+	})
+	assert.NoError(t, err)
+
+	dst := r.Redact(map[string]any{
+		"token":   "abc",
+		"secret":  "abc",
+		"card_no": "6222020200112233",
+		"phone":   "13812345678",
+		"rate":    0.35,
+		"enabled": true,
+		"profile": map[string]any{
+			"nick": "john",
+			"tags": []any{"a", 1},
+			"none": nil,
+		},
+	}).(map[string]any)
+	assert.Equal(t, maskHash("abc"), dst["token"])
+	assert.Equal(t, "******", dst["secret"])
+	assert.Equal(t, "************2233", dst["card_no"])
+	assert.Equal(t, "[11 chars]", dst["phone"])
+	assert.Equal(t, "******", dst["rate"])
+	assert.Equal(t, "******", dst["enabled"])
+	profile := dst["profile"].(map[string]any)
+	assert.Equal(t, "******", profile["nick"])
+	assert.Equal(t, []any{"******", "******"}, profile["tags"])
+	assert.Nil(t, profile["none"])
+
+	_, err = newRedactor(&Option{MaskStrategies: map[string]string{"token": "unknown"}})
+	assert.Error(t, err)
+	_, err = newRedactor(&Option{DefaultMaskStrategy: "unknown"})
+	assert.Error(t, err)
+}
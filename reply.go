@@ -2,21 +2,96 @@ package rpclient
 
 import (
 	"fmt"
+	"slices"
 
 	"gopkg.in/guregu/null.v4"
 )
 
 type Reply struct {
-	RequestId string   `json:"request_id"`
-	Results   []Result `json:"results"`
+	RequestId          string   `json:"request_id"`
+	PreviousRequestIds []string `json:"previous_request_ids,omitempty"` // 合并前的请求 ID，按时间顺序排列
+	Results            []Result `json:"results"`
 }
 
 func (r *Reply) Reset() *Reply {
 	r.RequestId = ""
+	r.PreviousRequestIds = nil
 	r.Results = make([]Result, 0)
 	return r
 }
 
+// FailedStoreIds 失败结果对应的店铺 ID（去重）
+func (r *Reply) FailedStoreIds() []string {
+	var storeIds []string
+	for _, result := range r.Results {
+		if !result.Ok && !slices.Contains(storeIds, result.StoreId) {
+			storeIds = append(storeIds, result.StoreId)
+		}
+	}
+	return storeIds
+}
+
+// MergeAt 将调用 args 得到的 other 合并到当前回应，args 中第 i 个查询的结果写入 r.Results[slots[i]]
+//
+// args、slots 通常为 Args.Failed 的返回值，没有对应位置的结果追加到末尾；当前的 RequestId 记入 PreviousRequestIds，并更新为 other.RequestId。
+func (r *Reply) MergeAt(slots []int, args Args, other *Reply) *Reply {
+	r.mergeRequestId(other)
+
+	matched := make([]bool, len(other.Results))
+	for i, j := range resultIndexes(args, other.Results) {
+		if j < 0 {
+			continue
+		}
+		matched[j] = true
+		if i < len(slots) && slots[i] >= 0 && slots[i] < len(r.Results) {
+			r.Results[slots[i]] = other.Results[j]
+		} else {
+			r.Results = append(r.Results, other.Results[j])
+		}
+	}
+	for j, result := range other.Results {
+		if !matched[j] {
+			r.Results = append(r.Results, result)
+		}
+	}
+	return r
+}
+
+// mergeRequestId 将当前的 RequestId 记入 PreviousRequestIds，并更新为 other.RequestId
+func (r *Reply) mergeRequestId(other *Reply) {
+	if other.RequestId == "" {
+		return
+	}
+	if r.RequestId != "" {
+		r.PreviousRequestIds = append(r.PreviousRequestIds, r.RequestId)
+	}
+	r.PreviousRequestIds = append(r.PreviousRequestIds, other.PreviousRequestIds...)
+	r.RequestId = other.RequestId
+}
+
+// resultIndexes 返回 args 中每个查询的结果在 results 中的位置，没有结果时为 -1
+// 同一店铺的第 n 个查询对应该店铺的第 n 个结果
+func resultIndexes(args Args, results []Result) []int {
+	indexes := make([]int, len(args))
+	seen := make(map[string]int)
+	for i, payload := range args {
+		indexes[i] = -1
+		n := seen[payload.Store.ID]
+		seen[payload.Store.ID]++
+		for j, result := range results {
+			if result.StoreId != payload.Store.ID {
+				continue
+			}
+			if n == 0 {
+				indexes[i] = j
+				break
+			}
+			n--
+		}
+	}
+	return indexes
+}
+
 // HasError 回应数据中是否存在错误
 func (r *Reply) HasError() bool {
 	for _, result := range r.Results {
@@ -0,0 +1,63 @@
+package rpclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReply_FailedStoreIds(t *testing.T) {
+	r := Reply{
+		RequestId:          "a",
+		PreviousRequestIds: []string{"x"},
+		Results: []Result{
+			{StoreId: "1", Ok: true, Data: 1},
+			{StoreId: "2", Ok: false},
+			{StoreId: "3", Ok: false, Data: "x"},
+			{StoreId: "3", Ok: false, Data: "y"},
+		},
+	}
+	assert.Equal(t, []string{"2", "3"}, r.FailedStoreIds())
+
+	r.Reset()
+	assert.Nil(t, r.PreviousRequestIds)
+	assert.Nil(t, r.FailedStoreIds())
+}
+
+func TestReply_MergeAt(t *testing.T) {
+	args := NewArgs().
+		Add(NewPayload(testStore("1"), "a")).
+		Add(NewPayload(testStore("1"), "b")).
+		Add(NewPayload(testStore("2"), "c"))
+	r := Reply{
+		RequestId: "a",
+		Results: []Result{
+			{StoreId: "1", Ok: true, Data: "a"},
+			{StoreId: "1", Ok: false, Data: "b"},
+			{StoreId: "2", Ok: true, Data: "c"},
+		},
+	}
+
+	// 同一店铺中已成功的查询不会被重新调用
+	failed, slots := args.Failed(&r)
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, "b", failed[0].Body)
+	}
+	assert.Equal(t, []int{1}, slots)
+
+	r.MergeAt(slots, failed, &Reply{
+		RequestId: "b",
+		Results:   []Result{{StoreId: "1", Ok: true, Data: "b2"}},
+	})
+	assert.Equal(t, []Result{
+		{StoreId: "1", Ok: true, Data: "a"},
+		{StoreId: "1", Ok: true, Data: "b2"},
+		{StoreId: "2", Ok: true, Data: "c"},
+	}, r.Results)
+	assert.Equal(t, "b", r.RequestId)
+	assert.Equal(t, []string{"a"}, r.PreviousRequestIds)
+
+	failed, slots = args.Failed(&r)
+	assert.Empty(t, failed)
+	assert.Empty(t, slots)
+}
@@ -0,0 +1,147 @@
+package rpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// Resolver 返回当前可用的端点地址列表，用于 ClusterOption.Resolver
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// Watcher 可选接口，Resolver 实现后 ClusterClient 会在端点可能发生变化时立即刷新，不必等待 ResolveInterval
+//
+// Watch 返回的 channel 在 ctx 结束后关闭。
+type Watcher interface {
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// ResolveFunc 将函数转换为 Resolver
+type ResolveFunc func(ctx context.Context) ([]string, error)
+
+func (f ResolveFunc) Resolve(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+// SRVResolver 通过 DNS SRV 记录获取端点，查询 _Service._Proto.Name
+//
+// 端点按 net.Resolver.LookupSRV 返回的顺序（优先级、权重）排列，ClusterClient 在所有端点之间分配调用。
+type SRVResolver struct {
+	Service  string
+	Proto    string
+	Name     string
+	Resolver *net.Resolver // 为 nil 时使用 net.DefaultResolver
+}
+
+// NewSRVResolver 创建 DNS SRV 端点解析器，如 NewSRVResolver("rpc", "tcp", "example.com") 查询 _rpc._tcp.example.com
+func NewSRVResolver(service, proto, name string) *SRVResolver {
+	return &SRVResolver{Service: service, Proto: proto, Name: name}
+}
+
+func (r *SRVResolver) Resolve(ctx context.Context) ([]string, error) {
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, r.Service, r.Proto, r.Name)
+	if err != nil {
+		return nil, fmt.Errorf("rpclient: lookup SRV: %w", err)
+	}
+	addrs := make([]string, 0, len(records))
+	for _, record := range records {
+		addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+	}
+	return addrs, nil
+}
+
+// FileResolver 从文件中读取端点，文件内容变化时通知 ClusterClient 刷新
+//
+// 文件为 JSON 数组，或每行一个地址的文本（忽略空行及 # 开头的注释）：
+//
+//	# rpc endpoints
+//	10.0.0.1:6001
+//	10.0.0.2:6001
+type FileResolver struct {
+	Path     string
+	Interval int // 检查文件变化的间隔（毫秒），默认 1000
+}
+
+// NewFileResolver 创建从文件读取端点的解析器
+func NewFileResolver(path string) *FileResolver {
+	return &FileResolver{Path: path}
+}
+
+func (r *FileResolver) Resolve(_ context.Context) ([]string, error) {
+	b, err := os.ReadFile(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("rpclient: read endpoint file: %w", err)
+	}
+	addrs, err := parseEndpoints(b)
+	if err != nil {
+		return nil, fmt.Errorf("rpclient: parse endpoint file %s: %w", r.Path, err)
+	}
+	return addrs, nil
+}
+
+// Watch 定时读取文件，内容与上次不同时发出通知
+func (r *FileResolver) Watch(ctx context.Context) <-chan struct{} {
+	interval := time.Duration(r.Interval) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last, _ := os.ReadFile(r.Path)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			b, err := os.ReadFile(r.Path)
+			if err != nil || bytes.Equal(b, last) {
+				continue
+			}
+			last = b
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changes
+}
+
+// parseEndpoints 解析 JSON 数组或每行一个地址的文本
+func parseEndpoints(b []byte) ([]string, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("[")) {
+		var addrs []string
+		if err := json.Unmarshal(b, &addrs); err != nil {
+			return nil, err
+		}
+		return addrs, nil
+	}
+
+	var addrs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			addrs = append(addrs, line)
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,143 @@
+package rpclient
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSRVTestResolver 启动一个只应答 SRV 查询的本地 DNS 服务端，返回使用它的 net.Resolver
+func newSRVTestResolver(t *testing.T, records []*net.SRV) *net.Resolver {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = pc.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// 跳过 QNAME 后是 QTYPE、QCLASS
+			end := 12
+			for end < n && buf[end] != 0 {
+				end += int(buf[end]) + 1
+			}
+			end += 5
+			if end > n {
+				continue
+			}
+			isSRV := binary.BigEndian.Uint16(buf[end-4:]) == 33
+
+			msg := append([]byte{}, buf[:2]...)              // ID
+			msg = binary.BigEndian.AppendUint16(msg, 0x8180) // 响应、期望递归、支持递归
+			msg = binary.BigEndian.AppendUint16(msg, 1)      // QDCOUNT
+			msg = binary.BigEndian.AppendUint16(msg, 0)      // ANCOUNT，稍后填写
+			msg = binary.BigEndian.AppendUint32(msg, 0)      // NSCOUNT、ARCOUNT
+			msg = append(msg, buf[12:end]...)
+			if isSRV {
+				binary.BigEndian.PutUint16(msg[6:], uint16(len(records)))
+				for _, record := range records {
+					var target []byte
+					for _, label := range strings.Split(strings.TrimSuffix(record.Target, "."), ".") {
+						target = append(target, byte(len(label)))
+						target = append(target, label...)
+					}
+					target = append(target, 0)
+
+					msg = append(msg, 0xC0, 12)                  // 指向问题中的名称
+					msg = binary.BigEndian.AppendUint16(msg, 33) // SRV
+					msg = binary.BigEndian.AppendUint16(msg, 1)  // IN
+					msg = binary.BigEndian.AppendUint32(msg, 60)
+					msg = binary.BigEndian.AppendUint16(msg, uint16(6+len(target)))
+					msg = binary.BigEndian.AppendUint16(msg, record.Priority)
+					msg = binary.BigEndian.AppendUint16(msg, record.Weight)
+					msg = binary.BigEndian.AppendUint16(msg, record.Port)
+					msg = append(msg, target...)
+				}
+			}
+			_, _ = pc.WriteTo(msg, addr)
+		}
+	}()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", pc.LocalAddr().String())
+		},
+	}
+}
+
+func TestSRVResolver(t *testing.T) {
+	r := NewSRVResolver("rpc", "tcp", "example.test")
+	r.Resolver = newSRVTestResolver(t, []*net.SRV{
+		{Target: "rpc1.example.test.", Port: 6001, Priority: 10, Weight: 1},
+		{Target: "rpc2.example.test.", Port: 6002, Priority: 20, Weight: 1},
+	})
+	addrs, err := r.Resolve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rpc1.example.test:6001", "rpc2.example.test:6002"}, addrs)
+
+	r.Resolver = newSRVTestResolver(t, nil)
+	_, err = r.Resolve(context.Background())
+	assert.ErrorContains(t, err, "rpclient: lookup SRV")
+}
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	r := NewFileResolver(path)
+	_, err := r.Resolve(context.Background())
+	assert.ErrorContains(t, err, "rpclient: read endpoint file")
+
+	assert.NoError(t, os.WriteFile(path, []byte("# rpc\n10.0.0.1:6001\n\n 10.0.0.2:6001 # backup\n"), 0o600))
+	addrs, err := r.Resolve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:6001", "10.0.0.2:6001"}, addrs)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`["10.0.0.3:6001"]`), 0o600))
+	addrs, err = r.Resolve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.3:6001"}, addrs)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`["10.0.0.3:6001"`), 0o600))
+	_, err = r.Resolve(context.Background())
+	assert.ErrorContains(t, err, "rpclient: parse endpoint file")
+}
+
+func TestClusterClient_FileResolver(t *testing.T) {
+	a, b := newNodeTestServer(t), newNodeTestServer(t)
+	path := filepath.Join(t.TempDir(), "endpoints")
+	assert.NoError(t, os.WriteFile(path, []byte(a.addr), 0o600))
+
+	// ResolveInterval 足够长，端点只能通过 Watch 更新
+	c := newTestCluster(t, nil, &ClusterOption{
+		ResolveInterval: 60,
+		Resolver:        &FileResolver{Path: path, Interval: 20},
+	})
+	assert.Equal(t, a.addr, callNode(t, c, "Node.Addr"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(b.addr+"\n"), 0o600))
+	assert.Eventually(t, func() bool {
+		statuses := c.Endpoints()
+		return len(statuses) == 1 && statuses[0].Addr == b.addr
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, b.addr, callNode(t, c, "Node.Addr"))
+
+	// 文件暂时不可读或为空时保留当前端点
+	assert.NoError(t, os.WriteFile(path, nil, 0o600))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, b.addr, callNode(t, c, "Node.Addr"))
+}
@@ -21,6 +21,7 @@ type Result struct {
 
 // ConvertDataTo 将 Data 数据提取到指定的结构体中
 // 因为使用的是 json.Unmarshal 所以请确保 `json` 标签的正确性，否则可能会导致数据丢失
+// Data 中键不是字符串的 map（如 map[any]any）会先转换为 map[string]any
 func (r Result) ConvertDataTo(dstPtr any) error {
 	if dstPtr == nil {
 		return errors.New("rpclient: 'dstPtr' param value cannot be nil")
@@ -56,7 +57,8 @@ func (r Result) ConvertDataTo(dstPtr any) error {
 		return fmt.Errorf("rpclient: %s cannot be converted to %s", srcKind, dstKind)
 	}
 
-	b, err := json.Marshal(r.Data)
+	// MessagePack 等编解码器可能将 map 解码为 map[any]any，JSON 无法直接编码
+	b, err := json.Marshal(normalizeData(r.Data))
 	if err != nil {
 		return fmt.Errorf("rpclient: failed to marshal data: %w", err)
 	}
@@ -0,0 +1,127 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+)
+
+// RetryOption 调用重试配置
+//
+// 只有匹配 Idempotent 的服务方法才会重试，避免创建发货单之类的非幂等调用被重复执行。
+type RetryOption struct {
+	MaxAttempts int                  `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"` // 最多尝试次数（含首次调用），小于 2 表示不重试
+	Backoff     Backoff              `json:"backoff" yaml:"backoff" toml:"backoff"`                // 重试间隔
+	Idempotent  []string             `json:"idempotent" yaml:"idempotent" toml:"idempotent"`       // 可安全重试的服务方法，支持完整名称及通配符，如 `Temu.*.Query`、`Temu.Semi.Order.*`
+	Retryable   func(err error) bool `json:"-" yaml:"-" toml:"-"`                                  // 判断错误是否可重试，为 nil 时使用 IsRetryable
+}
+
+// IsRetryable 默认的可重试错误判断，仅重试连接断开、正在重连及网络错误
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := AsTimeoutError(err); ok {
+		return false
+	}
+	err = cause(err)
+	if isConnError(err) || errors.Is(err, ErrNotReady) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
+
+// matchServiceMethod 判断服务方法是否匹配 patterns 中的任意一项
+// 通配符规则同 path.Match，`*` 可匹配包括 `.` 在内的任意字符
+func matchServiceMethod(patterns []string, serviceMethod string) bool {
+	for _, pattern := range patterns {
+		if pattern == serviceMethod {
+			return true
+		}
+		if ok, _ := path.Match(pattern, serviceMethod); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterIdempotent 登记可安全重试的服务方法，规则同 RetryOption.Idempotent
+func (c *RpcClient) RegisterIdempotent(patterns ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idempotent = append(c.idempotent, patterns...)
+}
+
+// IsIdempotent 服务方法是否已登记为可安全重试
+func (c *RpcClient) IsIdempotent(serviceMethod string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return matchServiceMethod(c.idempotent, serviceMethod)
+}
+
+// invokeWithRetry 发起调用，幂等的服务方法在遇到可重试错误时按退避策略重试
+func (c *RpcClient) invokeWithRetry(ctx context.Context, serviceMethod string, args Args, reply *Reply) error {
+	retry := c.option.Retry
+	attempts := 1
+	if retry.MaxAttempts > 1 && c.IsIdempotent(serviceMethod) {
+		attempts = retry.MaxAttempts
+	}
+	retryable := retry.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.invoke(ctx, serviceMethod, args, reply)
+		if err == nil || attempt+1 >= attempts || !retryable(err) {
+			return err
+		}
+
+		wait := retry.Backoff.Duration(attempt)
+		c.logger.Warn("Retry", "serviceMethod", serviceMethod, "attempt", attempt+1, "wait", wait.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return c.contextError(ctx, serviceMethod, args, callStart(ctx))
+		case <-time.After(wait):
+		}
+	}
+}
+
+// CallWithStoreRetry calls the RPC server like CallContext and then re-calls
+// only the stores whose Result.Ok is false, at most attempts times in total.
+//
+// Only the failed payloads are re-sent, see Args.Failed: payloads of the same
+// store that already succeeded are not called again. The new results are
+// merged back into their own slots of reply, see Reply.MergeAt. Retries are
+// spaced by Option.Retry.Backoff. If a retry fails at the transport level, its
+// error is returned and reply keeps the results gathered so far.
+func (c *RpcClient) CallWithStoreRetry(ctx context.Context, serviceMethod string, args Args, reply *Reply, attempts int) error {
+	if err := c.CallContext(ctx, serviceMethod, args, reply); err != nil {
+		return err
+	}
+
+	for attempt := 1; attempt < attempts && reply.HasError(); attempt++ {
+		failed, slots := args.Failed(reply)
+		if failed.IsEmpty() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return rrse.E(rrse.Op("call"), ctx.Err())
+		case <-time.After(c.option.Retry.Backoff.Duration(attempt - 1)):
+		}
+
+		var r Reply
+		if err := c.CallContext(ctx, serviceMethod, failed, &r); err != nil {
+			return err
+		}
+		reply.MergeAt(slots, failed, &r)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package rpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/rpc"
+	"testing"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchServiceMethod(t *testing.T) {
+	patterns := []string{"Temu.*.Query", "Shein.Order.Detail", "Tiktok.Goods.*"}
+	tests := map[string]bool{
+		"Temu.Semi.Order.Query":       true,
+		"Temu.Goods.Query":            true,
+		"Temu.Semi.Order.Ship":        false,
+		"Shein.Order.Detail":          true,
+		"Shein.Order.DetailList":      false,
+		"Tiktok.Goods.Detail":         true,
+		"Tiktok.Shipment.Create":      false,
+		"Temu.Semi.Order.QueryDetail": false,
+	}
+	for serviceMethod, want := range tests {
+		assert.Equalf(t, want, matchServiceMethod(patterns, serviceMethod), "%s", serviceMethod)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.True(t, IsRetryable(rrse.E(rrse.Op("call"), rpc.ErrShutdown)))
+	assert.True(t, IsRetryable(rrse.E(rrse.Op("call"), io.ErrUnexpectedEOF)))
+	assert.True(t, IsRetryable(rrse.E(rrse.Op("call"), rrse.Network, ErrNotReady)))
+	assert.False(t, IsRetryable(rrse.E(rrse.Op("call"), rpc.ServerError("bad request"))))
+	assert.False(t, IsRetryable(rrse.E(rrse.Op("call"), rrse.TimeOut, &TimeoutError{Err: errors.New("deadline")})))
+}
+
+func TestRpcClient_Retry(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Backoff = Backoff{Initial: 10, Max: 20}
+	opt.Retry = RetryOption{
+		MaxAttempts: 5,
+		Backoff:     Backoff{Initial: 50, Max: 100},
+		Idempotent:  []string{"Test.Echo"},
+	}
+	client := newTestClient(t, ts.addr, &opt)
+	args := NewArgs().Add(NewPayload(testStore("1"), "x"))
+
+	// 幂等方法在连接断开后自动重试
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+	var r Reply
+	err := client.Call("Test.Echo", args, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.Results))
+
+	// 非幂等方法不重试
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+	err = client.Call("Test.Sleep", args, &r)
+	assert.True(t, isConnError(err))
+	waitState(t, client, StateReady, 2*time.Second)
+
+	// 运行时登记幂等方法
+	client.RegisterIdempotent("Test.S*")
+	assert.True(t, client.IsIdempotent("Test.Sleep"))
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+	err = client.Call("Test.Sleep", args, &r)
+	assert.NoError(t, err)
+}
+
+func TestRpcClient_RetryTimeout(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Reconnect.Backoff = Backoff{Initial: 10, Max: 20}
+	opt.Retry = RetryOption{
+		MaxAttempts: 3,
+		Backoff:     Backoff{Initial: 1500, Max: 1500, Multiplier: 1},
+		Idempotent:  []string{"Test.Sleep"},
+	}
+	client := newTestClient(t, ts.addr, &opt)
+
+	// 第一次尝试因连接断开失败，重试的调用超过截止时间（店铺 2 的 2 秒加上 TimeoutOverhead 1 秒）
+	s1, s2 := testStore("1"), testStore("2")
+	s1.Timeout = 1
+	s2.Timeout = 2
+	args := NewArgs().Add(NewPayload(s1, 5000)).Add(NewPayload(s2, 0))
+	time.Sleep(50 * time.Millisecond)
+	ts.closeConns()
+	time.Sleep(50 * time.Millisecond)
+
+	var r Reply
+	err := client.Call("Test.Sleep", args, &r)
+	te, ok := AsTimeoutError(err)
+	if assert.True(t, ok, err) {
+		// 从第一次尝试开始计时，而不是最后一次
+		assert.GreaterOrEqual(t, te.Elapsed, 3*time.Second)
+		assert.Equal(t, []string{"1", "2"}, te.StoreIds)
+	}
+}
+
+func TestRpcClient_CallWithStoreRetry(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Retry.Backoff = Backoff{Initial: 10, Max: 10}
+	client := newTestClient(t, ts.addr, &opt)
+
+	// 店铺 1 失败 1 次，店铺 2 不失败，店铺 3 失败 5 次
+	args := NewArgs().
+		Add(NewPayload(testStore("1"), 1)).
+		Add(NewPayload(testStore("2"), 0)).
+		Add(NewPayload(testStore("3"), 5))
+	var r Reply
+	err := client.CallWithStoreRetry(context.Background(), "Test.Flaky", args, &r, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(r.Results))
+	assert.Equal(t, []string{"1", "2", "3"}, []string{r.Results[0].StoreId, r.Results[1].StoreId, r.Results[2].StoreId})
+	assert.True(t, r.Results[0].Ok)
+	assert.True(t, r.Results[1].Ok)
+	assert.False(t, r.Results[2].Ok)
+	assert.Equal(t, []string{"3"}, r.FailedStoreIds())
+	assert.Equal(t, 2, len(r.PreviousRequestIds))
+	assert.NotContains(t, r.PreviousRequestIds, r.RequestId)
+}
+
+func TestRpcClient_CallWithStoreRetrySameStore(t *testing.T) {
+	ts := newTestServer(t)
+	opt := testOption
+	opt.Retry.Backoff = Backoff{Initial: 10}
+	client := newTestClient(t, ts.addr, &opt)
+
+	// 店铺 1 的第一个查询成功，第二个查询失败，只重新发送失败的查询
+	failing := testStore("1")
+	failing.Configuration["fail"] = true
+	args := NewArgs().Add(NewPayload(testStore("1"), "a")).Add(NewPayload(failing, "b"))
+	var calls []Args
+	client.invoker = chainInvoker([]UnaryInterceptor{func(ctx context.Context, serviceMethod string, args Args, reply *Reply, invoker Invoker) error {
+		calls = append(calls, args)
+		return invoker(ctx, serviceMethod, args, reply)
+	}}, client.invoke)
+
+	var r Reply
+	assert.NoError(t, client.CallWithStoreRetry(context.Background(), "Test.Echo", args, &r, 3))
+	if assert.Len(t, calls, 3) {
+		assert.Len(t, calls[1], 1)
+		assert.Equal(t, "b", calls[1][0].Body)
+	}
+	if assert.Len(t, r.Results, 2) {
+		assert.True(t, r.Results[0].Ok)
+		assert.Equal(t, "a", r.Results[0].Data)
+		assert.False(t, r.Results[1].Ok)
+		assert.Equal(t, "b", r.Results[1].Data)
+	}
+}
@@ -0,0 +1,66 @@
+package rpclient
+
+import (
+	"context"
+	"time"
+)
+
+// storeTimeout 店铺的超时时间，未设置时使用 Option.Timeout
+func (c *RpcClient) storeTimeout(store Store) time.Duration {
+	timeout := store.Timeout
+	if timeout <= 0 {
+		timeout = c.option.Timeout
+	}
+	if timeout <= 0 {
+		return 0
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// callTimeout 计算一次调用的超时时间
+// 取所有店铺超时时间的最大值再加上 Option.TimeoutOverhead，返回 0 表示不限制；
+// 任一店铺不限制超时时间（Store.Timeout、Option.Timeout 均未设置）时整个调用都不限制
+func (c *RpcClient) callTimeout(args Args) time.Duration {
+	if args.IsEmpty() {
+		args = Args{{}}
+	}
+	var timeout time.Duration
+	for _, arg := range args {
+		d := c.storeTimeout(arg.Store)
+		if d == 0 {
+			return 0
+		}
+		timeout = max(timeout, d)
+	}
+	if c.option.TimeoutOverhead > 0 {
+		timeout += time.Duration(c.option.TimeoutOverhead) * time.Second
+	}
+	return timeout
+}
+
+// callStartKey 调用开始时间在 ctx 中的键，重试时 TimeoutError 仍从第一次尝试开始计时
+type callStartKey struct{}
+
+// withCallStart 记录调用开始时间，由 CallContext 在重试之前调用
+func withCallStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callStartKey{}, time.Now())
+}
+
+// callStart 返回 withCallStart 记录的调用开始时间，未记录时返回当前时间
+func callStart(ctx context.Context) time.Time {
+	if start, ok := ctx.Value(callStartKey{}).(time.Time); ok {
+		return start
+	}
+	return time.Now()
+}
+
+// exceededStores 返回已等待时间超出自身超时时间的店铺 ID
+func (c *RpcClient) exceededStores(args Args, elapsed time.Duration) []string {
+	var storeIds []string
+	for _, arg := range args {
+		if d := c.storeTimeout(arg.Store); d > 0 && d <= elapsed {
+			storeIds = append(storeIds, arg.Store.ID)
+		}
+	}
+	return storeIds
+}
@@ -0,0 +1,62 @@
+package rpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rrse "github.com/roadrunner-server/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRpcClient_callTimeout(t *testing.T) {
+	c := &RpcClient{option: &Option{Timeout: 5, TimeoutOverhead: 1}}
+	s1, s2 := testStore("1"), testStore("2")
+	s2.Timeout = 10
+
+	assert.Equal(t, 6*time.Second, c.callTimeout(NewArgs()))
+	assert.Equal(t, 6*time.Second, c.callTimeout(NewArgs().Add(NewPayload(s1))))
+	assert.Equal(t, 11*time.Second, c.callTimeout(NewArgs().Add(NewPayload(s1)).Add(NewPayload(s2))))
+	assert.Equal(t, []string{"1"}, c.exceededStores(NewArgs().Add(NewPayload(s1)).Add(NewPayload(s2)), 6*time.Second))
+
+	// 任一店铺不限制超时时间时整个调用都不限制
+	c = &RpcClient{option: &Option{TimeoutOverhead: 1}}
+	assert.Equal(t, time.Duration(0), c.callTimeout(NewArgs()))
+	assert.Equal(t, time.Duration(0), c.callTimeout(NewArgs().Add(NewPayload(s1))))
+	assert.Equal(t, time.Duration(0), c.callTimeout(NewArgs().Add(NewPayload(s1)).Add(NewPayload(s2))))
+	assert.Equal(t, 11*time.Second, c.callTimeout(NewArgs().Add(NewPayload(s2))))
+
+	// 自定义的 Option 未设置 TimeoutOverhead 时使用默认值
+	c = &RpcClient{option: (&Option{Timeout: 5}).withDefaults()}
+	assert.Equal(t, 6*time.Second, c.callTimeout(NewArgs().Add(NewPayload(s1))))
+}
+
+func TestRpcClient_CallStoreTimeout(t *testing.T) {
+	ts := newTestServer(t)
+	client := newTestClient(t, ts.addr, &Option{Network: "tcp", Codec: JsonCodec, LogLevel: "error"})
+
+	s1, s2 := testStore("1"), testStore("2")
+	s1.Timeout = 1
+	s2.Timeout = 2
+	args := NewArgs().Add(NewPayload(s1, 5000)).Add(NewPayload(s2, 0))
+	var r Reply
+	start := time.Now()
+	err := client.Call("Test.Sleep", args, &r)
+	assert.Error(t, err)
+	assert.True(t, rrse.Is(rrse.TimeOut, err))
+	// 店铺 2 的 2 秒加上默认的 TimeoutOverhead 1 秒
+	assert.Less(t, time.Since(start), 4*time.Second)
+	te, ok := AsTimeoutError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"1", "2"}, te.StoreIds)
+	}
+
+	// 调用方的截止时间更早时，只报告已超出自身超时时间的店铺
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	err = client.CallContext(ctx, "Test.Sleep", args, &r)
+	te, ok = AsTimeoutError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"1"}, te.StoreIds)
+	}
+}
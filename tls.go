@@ -0,0 +1,77 @@
+package rpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+)
+
+// tlsVersions MinVersion 支持的取值
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSOption TLS 传输配置，证书均为 PEM 格式的文件路径
+//
+// 设置 CertFile、KeyFile 时向服务端出示客户端证书（双向 TLS）。
+type TLSOption struct {
+	Enabled            bool        `json:"enabled" yaml:"enabled" toml:"enabled"`                                        // 是否使用 TLS 连接
+	CAFile             string      `json:"ca_file" yaml:"ca_file" toml:"ca_file"`                                        // 用于校验服务端证书的 CA 证书，为空时使用系统证书
+	CertFile           string      `json:"cert_file" yaml:"cert_file" toml:"cert_file"`                                  // 客户端证书
+	KeyFile            string      `json:"key_file" yaml:"key_file" toml:"key_file"`                                     // 客户端证书私钥
+	ServerName         string      `json:"server_name" yaml:"server_name" toml:"server_name"`                            // 校验服务端证书使用的主机名，为空时使用连接地址中的主机名
+	MinVersion         string      `json:"min_version" yaml:"min_version" toml:"min_version"`                            // 最低 TLS 版本：1.0、1.1、1.2、1.3，默认 1.2
+	InsecureSkipVerify bool        `json:"insecure_skip_verify" yaml:"insecure_skip_verify" toml:"insecure_skip_verify"` // 不校验服务端证书，仅用于测试
+	Config             *tls.Config `json:"-" yaml:"-" toml:"-"`                                                          // 自定义 TLS 配置，设置后忽略以上证书相关配置
+}
+
+// config 根据配置创建 tls.Config，未启用时返回 nil
+func (o TLSOption) config(addr string) (*tls.Config, error) {
+	if !o.Enabled {
+		return nil, nil
+	}
+	if o.Config != nil {
+		return o.Config.Clone(), nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         o.ServerName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify, //nolint:gosec
+	}
+	if cfg.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			cfg.ServerName = host
+		}
+	}
+	if o.MinVersion != "" {
+		version, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("rpclient: unknown TLS version %q", o.MinVersion)
+		}
+		cfg.MinVersion = version
+	}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("rpclient: read CA file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("rpclient: no certificates found in CA file %q", o.CAFile)
+		}
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("rpclient: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
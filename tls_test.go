@@ -0,0 +1,163 @@
+package rpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCA 测试用的自签名 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rpclient test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, filepath.Join(ca.dir, "ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+// issue 签发证书并写入 name.pem、name-key.pem
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(ca.dir, name+".pem"), filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTLSTestServer 启动要求客户端证书的 TLS 测试服务端
+func newTLSTestServer(t *testing.T, ca *testCA, newCodec func(conn io.ReadWriteCloser) rpc.ServerCodec) *testServer {
+	t.Helper()
+	certFile, keyFile := ca.issue(t, "rpc.local", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveTestServer(t, ln, newCodec)
+}
+
+func TestRpcClient_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	for codec, newCodec := range serverCodecs {
+		ts := newTLSTestServer(t, ca, newCodec)
+		opt := testOption
+		opt.Codec = codec
+		opt.TLS = TLSOption{
+			Enabled:    true,
+			CAFile:     filepath.Join(ca.dir, "ca.pem"),
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "rpc.local",
+			MinVersion: "1.3",
+		}
+		client := newTestClient(t, ts.addr, &opt)
+		var r Reply
+		assert.NoError(t, client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"), "secure")), &r), codec)
+		assert.Equal(t, "secure", r.Results[0].Data, codec)
+
+		// 未出示客户端证书
+		opt.TLS.CertFile, opt.TLS.KeyFile = "", ""
+		client, err := NewClient(ts.addr, &opt)
+		if err == nil {
+			err = client.Call("Test.Echo", NewArgs().Add(NewPayload(testStore("1"))), &r)
+			_ = client.Close()
+		}
+		assert.Error(t, err, codec)
+	}
+}
+
+func TestTLSOption_config(t *testing.T) {
+	cfg, err := TLSOption{}.config("127.0.0.1:6001")
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	cfg, err = TLSOption{Enabled: true}.config("rpc.local:6001")
+	assert.NoError(t, err)
+	assert.Equal(t, "rpc.local", cfg.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+
+	_, err = TLSOption{Enabled: true, MinVersion: "1.4"}.config("")
+	assert.EqualError(t, err, `rpclient: unknown TLS version "1.4"`)
+	_, err = TLSOption{Enabled: true, CAFile: "not-exists.pem"}.config("")
+	assert.ErrorContains(t, err, "read CA file")
+
+	_, err = NewClient("127.0.0.1:6001", &Option{Network: "tcp", Codec: JsonCodec, TLS: TLSOption{Enabled: true, CertFile: "not-exists.pem", KeyFile: "not-exists-key.pem"}})
+	assert.ErrorContains(t, err, "load client certificate")
+}